@@ -0,0 +1,221 @@
+package process
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Archive is a zip archive on disk, browsed without extracting it. Playwright
+// traces are only told apart, show-trace opens them as they are. Artifact is
+// set for an artifact inspected on GitHub, Path is then just its name.
+type Archive struct {
+	Path     string
+	Trace    bool
+	Entries  []ArchiveEntry
+	Artifact Artifact
+}
+
+// Remote reports whether the archive is an artifact inspected on GitHub.
+func (a Archive) Remote() bool {
+	return a.Artifact.ID != 0
+}
+
+// ArchiveEntry is a file or directory in an archive. Path is slash separated
+// and relative to the root of the archive. Directories that only exist as
+// part of the path of a file are listed too.
+type ArchiveEntry struct {
+	Path     string
+	Dir      bool
+	Size     int64
+	Modified time.Time
+}
+
+// Name is the last element of the entry's path.
+func (e ArchiveEntry) Name() string {
+	return path.Base(e.Path)
+}
+
+// Children are the entries directly inside dir, "" being the root.
+func (a Archive) Children(dir string) []ArchiveEntry {
+	if dir == "" {
+		dir = "."
+	}
+
+	var children []ArchiveEntry
+	for _, entry := range a.Entries {
+		if path.Dir(entry.Path) == dir {
+			children = append(children, entry)
+		}
+	}
+
+	return children
+}
+
+// OpenArchive lists the entries of the zip archive at archivePath.
+func OpenArchive(archivePath string) (Archive, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return Archive{}, extractionError("open "+filepath.Base(archivePath), err)
+	}
+	defer r.Close()
+
+	return Archive{
+		Path:    archivePath,
+		Trace:   isTrace(archivePath, &r.Reader),
+		Entries: archiveEntries(&r.Reader),
+	}, nil
+}
+
+// archiveEntries leaves out what extractZip would refuse or skip, so every
+// file listed can be extracted.
+func archiveEntries(archive *zip.Reader) []ArchiveEntry {
+	dirs := map[string]bool{}
+	var entries []ArchiveEntry
+
+	addDirs := func(name string) {
+		for dir := path.Dir(name); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+			entries = append(entries, ArchiveEntry{Path: dir, Dir: true})
+		}
+	}
+
+	for _, f := range archive.File {
+		name := strings.TrimSuffix(f.Name, "/")
+		if !filepath.IsLocal(name) {
+			continue
+		}
+
+		switch mode := f.Mode(); {
+		case mode.IsDir():
+			addDirs(name)
+			if !dirs[name] {
+				dirs[name] = true
+				entries = append(entries, ArchiveEntry{Path: name, Dir: true, Modified: f.Modified})
+			}
+
+		case mode.IsRegular():
+			addDirs(name)
+			entries = append(entries, ArchiveEntry{Path: name, Size: int64(f.UncompressedSize64), Modified: f.Modified})
+		}
+	}
+
+	// directories first, then by name
+	slices.SortFunc(entries, func(a ArchiveEntry, b ArchiveEntry) int {
+		if a.Dir != b.Dir {
+			if a.Dir {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return entries
+}
+
+// ExtractEntry extracts a single file of the archive into a directory of the
+// scratch directory that belongs to the archive, and returns where it was
+// extracted to.
+func ExtractEntry(archive Archive, entry ArchiveEntry) (string, error) {
+	r, err := zip.OpenReader(archive.Path)
+	if err != nil {
+		return "", extractionError("open "+filepath.Base(archive.Path), err)
+	}
+	defer r.Close()
+
+	dir, err := entryDir(archive.Path)
+	if err != nil {
+		return "", extractionError("extract "+entry.Path, err)
+	}
+
+	return extractEntry(&r.Reader, entry, dir, nil)
+}
+
+func extractEntry(archive *zip.Reader, entry ArchiveEntry, dir string, progress ProgressFunc) (string, error) {
+	for _, f := range archive.File {
+		if f.Name != entry.Path || !f.Mode().IsRegular() || !filepath.IsLocal(f.Name) {
+			continue
+		}
+
+		filePath := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := extractFile(f, filePath, newBudget(extractLimits), progress); err != nil {
+			// reading a remote archive can fail for other reasons
+			var e *Error
+			if errors.Is(err, ErrNoRanges) || errors.As(err, &e) {
+				return "", err
+			}
+			return "", extractionError("extract "+f.Name, err)
+		}
+		return filePath, nil
+	}
+
+	return "", extractionError("extract "+entry.Path, os.ErrNotExist)
+}
+
+// entryDir is the same for an archive as long as it isn't changed, so
+// extracting a file again overwrites the previous copy.
+func entryDir(archivePath string) (string, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s %d %d", abs, info.Size(), info.ModTime().UnixNano())
+
+	return scratchDir(fmt.Sprintf("%x", h.Sum64()))
+}
+
+// scratch is where files extracted from archives go until platui exits. It
+// is created on first use by os.MkdirTemp, so only the user can enter it and
+// nobody can have put anything there beforehand.
+var scratch struct {
+	sync.Mutex
+	dir string
+}
+
+// scratchDir returns the directory called name in the scratch directory,
+// creating both as needed.
+func scratchDir(name string) (string, error) {
+	scratch.Lock()
+	defer scratch.Unlock()
+
+	if scratch.dir == "" {
+		dir, err := os.MkdirTemp("", "platui-*")
+		if err != nil {
+			return "", err
+		}
+		scratch.dir = dir
+	}
+
+	dir := filepath.Join(scratch.dir, name)
+	return dir, os.MkdirAll(dir, 0700)
+}
+
+// RemoveScratch removes the files extracted from archives. Programs they were
+// opened in may still need them, so it is only called when platui exits.
+func RemoveScratch() error {
+	scratch.Lock()
+	defer scratch.Unlock()
+
+	if scratch.dir == "" {
+		return nil
+	}
+	dir := scratch.dir
+	scratch.dir = ""
+
+	return os.RemoveAll(dir)
+}
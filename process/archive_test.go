@@ -0,0 +1,137 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArchiveEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    []string
+	}{
+		{
+			"directories first",
+			[]entry{{name: "b.txt"}, {name: "a", dir: true}, {name: "a/c.txt"}},
+			[]string{"a/", "a/c.txt", "b.txt"},
+		},
+		{
+			"implied directories",
+			[]entry{{name: "report/data/screenshots.zip"}},
+			[]string{"report/", "report/data/", "report/data/screenshots.zip"},
+		},
+		{
+			"what extraction refuses or skips",
+			[]entry{{name: "../evil"}, {name: "/etc/passwd"}, {name: "latest", link: "report"}, {name: "ok.txt"}},
+			[]string{"ok.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range archiveEntries(buildZip(t, tt.entries)) {
+				name := e.Path
+				if e.Dir {
+					name += "/"
+				}
+				got = append(got, name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("archiveEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveChildren(t *testing.T) {
+	archive := Archive{Entries: archiveEntries(buildZip(t, []entry{{name: "a/b/c.txt"}, {name: "a/d.txt"}, {name: "e.txt"}}))}
+
+	tests := map[string][]string{
+		"":    {"a", "e.txt"},
+		"a":   {"a/b", "a/d.txt"},
+		"a/b": {"a/b/c.txt"},
+	}
+	for dir, want := range tests {
+		var got []string
+		for _, e := range archive.Children(dir) {
+			got = append(got, e.Path)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Children(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestOpenArchiveTellsTracesApart(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    bool
+	}{
+		{"trace.zip", []entry{{name: "trace.trace"}, {name: "trace.network"}, {name: "resources/a1b2.jpeg"}}, true},
+		{"TRACE.ZIP", []entry{{name: "notes.txt"}}, true},
+		// the HTML report keeps its traces by their digest
+		{"3f2a9c.zip", []entry{{name: "test.trace"}, {name: "resources/a1b2.jpeg"}}, true},
+		{"screenshots.zip", []entry{{name: "login.png"}}, false},
+		{"logs.zip", []entry{{name: "run/test.trace"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, zipBytes(t, tt.entries), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			archive, err := OpenArchive(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if archive.Trace != tt.want {
+				t.Fatalf("Trace = %v, want %v", archive.Trace, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractEntry(t *testing.T) {
+	t.Cleanup(func() { RemoveScratch() })
+
+	path := filepath.Join(t.TempDir(), "report.zip")
+	content := zipBytes(t, []entry{{name: "videos/checkout.webm", content: "webm"}, {name: "other.txt", content: "other"}})
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePath, err := ExtractEntry(archive, ArchiveEntry{Path: "videos/checkout.webm"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if content, err := os.ReadFile(filePath); err != nil || string(content) != "webm" {
+		t.Fatalf("extracted %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filePath), "..", "other.txt")); err == nil {
+		t.Error("extracted more than the entry")
+	}
+
+	root := scratch.dir
+	if info, err := os.Stat(root); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("scratch directory %s is %v, %v, want it private", root, info.Mode(), err)
+	}
+	if err := RemoveScratch(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Errorf("scratch directory is left behind: %v", err)
+	}
+}
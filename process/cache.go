@@ -0,0 +1,391 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// markerName is written into the directory of an artifact once it was
+// extracted completely. It is hidden from the filepicker by its leading dot.
+const markerName = ".platui.json"
+
+// marker identifies the artifact a directory holds. GitHub doesn't give us a
+// digest of the archive, the size and creation time tell apart an artifact
+// that was replaced. The marker's modification time is when the artifact was
+// last used.
+type marker struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	CreatedAt    time.Time `json:"created_at"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// Cache is where artifacts are downloaded to. Evict keeps it below MaxSize
+// bytes and removes artifacts that weren't used for MaxAge, a zero value
+// disabling either limit. With Unpack set, archives found inside artifacts
+// are unpacked too, artifacts already in the cache only once downloaded again.
+type Cache struct {
+	Dir     string
+	MaxSize int64
+	MaxAge  time.Duration
+	Unpack  bool
+}
+
+// CacheEntry is an artifact in the cache.
+type CacheEntry struct {
+	Path     string
+	ID       int64
+	Name     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// DefaultCacheDir is platui in the user's cache directory, which is
+// $XDG_CACHE_HOME or ~/.cache on Linux, or in the temporary directory when
+// there is none.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "platui")
+}
+
+// ArtifactDir is where DownloadArtifact extracts an artifact to.
+func (c *Cache) ArtifactDir(artifactId int64) string {
+	return filepath.Join(c.Dir, fmt.Sprint(artifactId))
+}
+
+// RunDir is where DownloadArtifacts extracts the artifacts of a run to, each
+// in a directory named after the artifact.
+func (c *Cache) RunDir(runId int64) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("run-%d", runId))
+}
+
+// artifactDir names the directory of an artifact within dir, artifact names
+// can't contain slashes but better safe than sorry.
+func artifactDir(dir string, artifact Artifact) (string, error) {
+	name := filepath.Base(filepath.Clean("/" + artifact.Name))
+	if name == "/" || name == "." {
+		return "", extractionError("extract "+artifact.Name, fmt.Errorf("invalid artifact name"))
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// IsCached reports whether DownloadArtifact would open artifact without
+// downloading it.
+func (c *Cache) IsCached(artifact Artifact) bool {
+	return isCached(c.ArtifactDir(artifact.ID), artifact)
+}
+
+func isCached(dir string, artifact Artifact) bool {
+	content, err := os.ReadFile(filepath.Join(dir, markerName))
+	if err != nil {
+		return false
+	}
+
+	var m marker
+	if err := json.Unmarshal(content, &m); err != nil {
+		return false
+	}
+
+	// archives are never empty, a size of 0 is one we don't know
+	return m.ID == artifact.ID && artifact.Size > 0 && m.Size == artifact.Size && m.CreatedAt.Equal(artifact.CreatedAt)
+}
+
+// Downloadable leaves out the expired artifacts that aren't cached either,
+// which can't be downloaded anymore.
+func (c *Cache) Downloadable(artifacts []Artifact) []Artifact {
+	var downloadable []Artifact
+	for _, artifact := range artifacts {
+		if !artifact.Expired || c.IsCached(artifact) {
+			downloadable = append(downloadable, artifact)
+		}
+	}
+
+	return downloadable
+}
+
+// copyCached fills dst with the copy of artifact DownloadArtifact left in the
+// cache, if there is one, and reports whether there was. Like a download it
+// only replaces dst once everything was copied.
+func (c *Cache) copyCached(artifact Artifact, dst string) (bool, error) {
+	src := c.ArtifactDir(artifact.ID)
+	if src == dst || !isCached(src, artifact) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, classify("copy artifact", err)
+	}
+	staging, err := createStaging(dst)
+	if err != nil {
+		return false, classify("copy artifact", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := copyDir(src, staging); err != nil {
+		return false, classify("copy artifact", err)
+	}
+
+	return true, replaceDir(staging, dst)
+}
+
+// copyDir copies the files, directories and symlinks in src into dst, which
+// exists already.
+func copyDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info)
+		}
+
+		return nil
+	})
+}
+
+func copyFile(src string, dst string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+func writeMarker(dir string, artifact Artifact) error {
+	content, err := json.Marshal(marker{
+		ID:           artifact.ID,
+		Name:         artifact.Name,
+		Size:         artifact.Size,
+		CreatedAt:    artifact.CreatedAt,
+		DownloadedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, markerName), content, 0644)
+}
+
+// Touch marks the artifacts in dir, or dir itself, as just used.
+func (c *Cache) Touch(dir string) {
+	now := time.Now()
+	os.Chtimes(filepath.Join(dir, markerName), now, now)
+
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			os.Chtimes(filepath.Join(dir, entry.Name(), markerName), now, now)
+		}
+	}
+}
+
+// Entries lists the artifacts in the cache, least recently used first. Only
+// completely extracted artifacts are listed.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == c.Dir && os.IsNotExist(err) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		// downloads being extracted and directories being replaced
+		if d.IsDir() && path != c.Dir && (strings.HasPrefix(d.Name(), stagingPrefix) || strings.HasPrefix(d.Name(), backupPrefix)) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || d.Name() != markerName {
+			return nil
+		}
+
+		entry, err := readEntry(filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, entry)
+
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, classify("list cache", err)
+	}
+
+	slices.SortFunc(entries, func(a, b CacheEntry) int {
+		return a.LastUsed.Compare(b.LastUsed)
+	})
+
+	return entries, nil
+}
+
+func readEntry(dir string) (CacheEntry, error) {
+	markerPath := filepath.Join(dir, markerName)
+	info, err := os.Stat(markerPath)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+
+	content, err := os.ReadFile(markerPath)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+
+	var m marker
+	if err := json.Unmarshal(content, &m); err != nil {
+		return CacheEntry{}, err
+	}
+
+	entry := CacheEntry{Path: dir, ID: m.ID, Name: m.Name, LastUsed: info.ModTime()}
+	err = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			entry.Size += info.Size()
+		}
+		return nil
+	})
+
+	return entry, err
+}
+
+// Delete removes an artifact from the cache, along with the directory of its
+// run when it was the last artifact in there.
+func (c *Cache) Delete(entry CacheEntry) error {
+	if !strings.HasPrefix(entry.Path, filepath.Clean(c.Dir)+string(os.PathSeparator)) {
+		return &Error{Kind: Unknown, Op: "delete " + entry.Path, Err: fmt.Errorf("not in the cache")}
+	}
+
+	if err := os.RemoveAll(entry.Path); err != nil {
+		return classify("delete "+entry.Name, err)
+	}
+
+	// only removes the run's directory if it is empty
+	if parent := filepath.Dir(entry.Path); parent != filepath.Clean(c.Dir) {
+		os.Remove(parent)
+	}
+
+	return nil
+}
+
+// Evict removes the artifacts that weren't used for MaxAge, and then the
+// least recently used ones until the cache fits into MaxSize. Artifacts below
+// any of the keep paths stay, they were just downloaded.
+func (c *Cache) Evict(keep ...string) error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	for _, entry := range entries {
+		kept := slices.ContainsFunc(keep, func(path string) bool {
+			return entry.Path == path || strings.HasPrefix(entry.Path, path+string(os.PathSeparator))
+		})
+		expired := c.MaxAge > 0 && time.Since(entry.LastUsed) > c.MaxAge
+		tooBig := c.MaxSize > 0 && total > c.MaxSize
+		if kept || (!expired && !tooBig) {
+			continue
+		}
+
+		if err := c.Delete(entry); err != nil {
+			return err
+		}
+		total -= entry.Size
+	}
+
+	return nil
+}
+
+// Downloads are extracted to a directory named with stagingPrefix next to
+// their destination, and replaceDir moves the destination it replaces aside
+// into one named with backupPrefix. Listing the cache skips both.
+const (
+	stagingPrefix = ".platui-staging-"
+	backupPrefix  = ".platui-backup-"
+)
+
+func createStaging(dst string) (string, error) {
+	return os.MkdirTemp(filepath.Dir(dst), stagingPrefix+"*")
+}
+
+// replaceDir renames src to dst, keeping the old dst around until the rename
+// succeeded. Every call backs up to a directory of its own, so concurrent
+// replacements of dst can't remove each other's backup.
+func replaceDir(src string, dst string) error {
+	backup := ""
+	if _, err := os.Stat(dst); err == nil {
+		dir, err := os.MkdirTemp(filepath.Dir(dst), backupPrefix+"*")
+		if err != nil {
+			return extractionError("replace "+dst, err)
+		}
+		defer os.RemoveAll(dir)
+
+		backup = filepath.Join(dir, filepath.Base(dst))
+		if err := os.Rename(dst, backup); err != nil {
+			return extractionError("replace "+dst, err)
+		}
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		if backup != "" {
+			os.Rename(backup, dst)
+		}
+		return extractionError("replace "+dst, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaceDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		existed bool
+		staged  bool
+		want    string
+		wantErr bool
+	}{
+		{"new", false, true, "new", false},
+		{"existing", true, true, "new", false},
+		{"failed rename keeps the old one", true, false, "old", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dst := filepath.Join(dir, "report")
+			src := filepath.Join(dir, "staged")
+			if tt.existed {
+				writeTestFile(t, filepath.Join(dst, "index.html"), "old")
+			}
+			if tt.staged {
+				writeTestFile(t, filepath.Join(src, "index.html"), "new")
+			}
+
+			err := replaceDir(src, dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("replaceDir() = %v", err)
+			}
+
+			if content, _ := os.ReadFile(filepath.Join(dst, "index.html")); string(content) != tt.want {
+				t.Errorf("index.html = %q, want %q", content, tt.want)
+			}
+			left, _ := filepath.Glob(filepath.Join(dir, backupPrefix+"*"))
+			if len(left) > 0 {
+				t.Errorf("backups left behind: %v", left)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDownloadableArtifacts(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	cached := Artifact{ID: 3, Name: "old-report", Size: 6, Expired: true}
+	if err := os.MkdirAll(cache.ArtifactDir(cached.ID), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeMarker(cache.ArtifactDir(cached.ID), cached); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts := []Artifact{
+		{ID: 1, Name: "report"},
+		{ID: 2, Name: "expired", Expired: true},
+		cached,
+	}
+	got := cache.Downloadable(artifacts)
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+		t.Fatalf("Downloadable() = %v, want the available and the cached artifact", got)
+	}
+}
+
+func TestIsCached(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cached := Artifact{ID: 900, Name: "playwright-report", Size: 6, CreatedAt: created}
+
+	tests := []struct {
+		name     string
+		marked   *Artifact
+		artifact Artifact
+		want     bool
+	}{
+		{"same artifact", &cached, cached, true},
+		{"same artifact in another time zone", &cached, Artifact{ID: 900, Size: 6, CreatedAt: created.In(time.FixedZone("CEST", 2*60*60))}, true},
+		{"not marked", nil, cached, false},
+		{"another artifact", &cached, Artifact{ID: 901, Size: 6, CreatedAt: created}, false},
+		{"another size", &cached, Artifact{ID: 900, Size: 7, CreatedAt: created}, false},
+		{"created again", &cached, Artifact{ID: 900, Size: 6, CreatedAt: created.Add(time.Hour)}, false},
+		{"unknown size", &Artifact{ID: 900, CreatedAt: created}, Artifact{ID: 900, CreatedAt: created}, false},
+		{"marked before creation times were", &Artifact{ID: 900, Size: 6}, cached, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.marked != nil {
+				if err := writeMarker(dir, *tt.marked); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := isCached(dir, tt.artifact); got != tt.want {
+				t.Fatalf("isCached() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadArtifactsCopiesCachedArtifacts(t *testing.T) {
+	fake := NewFake()
+	fake.Cache = &Cache{Dir: t.TempDir()}
+	artifact := Artifact{ID: 900, Name: "playwright-report", Size: 6}
+	fake.Files = map[int64]map[string]string{900: {"index.html": "<html>", "data/trace.zip": "trace"}}
+	ctx := context.Background()
+
+	if err := fake.DownloadArtifact(ctx, "acme", "web", artifact, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// GitHub no longer has it, the copy in the cache is all there is
+	artifact.Expired = true
+	delete(fake.Files, 900)
+
+	var last [2]int64
+	err := fake.DownloadArtifacts(ctx, "acme", "web", 7, []Artifact{artifact}, false, func(id int64, done int64, total int64) {
+		last = [2]int64{done, total}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(fake.Cache.RunDir(7), artifact.Name)
+	for name, want := range map[string]string{"index.html": "<html>", "data/trace.zip": "trace"} {
+		if content, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(content) != want {
+			t.Errorf("%s = %q, %v, want %q", name, content, err, want)
+		}
+	}
+	if !isCached(dir, artifact) {
+		t.Errorf("the copy isn't marked as cached")
+	}
+	if len(fake.Downloaded) != 1 {
+		t.Errorf("downloaded %v, want the first download only", fake.Downloaded)
+	}
+	if last != [2]int64{artifact.Size, artifact.Size} {
+		t.Errorf("last progress = %v, want it complete", last)
+	}
+}
+
+func TestCachedDownloadReportsProgress(t *testing.T) {
+	fake := NewFake()
+	fake.Cache = &Cache{Dir: t.TempDir()}
+	artifact := Artifact{ID: 900, Name: "playwright-report", Size: 6}
+	fake.Files = map[int64]map[string]string{900: {"index.html": "<html>"}}
+	ctx := context.Background()
+
+	if err := fake.DownloadArtifact(ctx, "acme", "web", artifact, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var last [2]int64
+	if err := fake.DownloadArtifact(ctx, "acme", "web", artifact, false, func(done int64, total int64) {
+		last = [2]int64{done, total}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if last != [2]int64{artifact.Size, artifact.Size} || len(fake.Downloaded) != 1 {
+		t.Fatalf("progress = %v after %d downloads, want it complete after one", last, len(fake.Downloaded))
+	}
+}
+
+// cacheArtifact puts an artifact of size bytes into dir, last used at used.
+func cacheArtifact(t *testing.T, dir string, id int64, size int, used time.Time) {
+	t.Helper()
+
+	writeTestFile(t, filepath.Join(dir, "file"), strings.Repeat("x", size))
+	if err := writeMarker(dir, Artifact{ID: id, Name: filepath.Base(dir), Size: int64(size)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, markerName), used, used); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	now := time.Now()
+	cacheArtifact(t, cache.ArtifactDir(1), 1, 10, now.Add(-time.Hour))
+	cacheArtifact(t, filepath.Join(cache.RunDir(7), "report"), 2, 10, now.Add(-2*time.Hour))
+	cacheArtifact(t, filepath.Join(cache.RunDir(7), ".hidden"), 3, 10, now)
+	cacheArtifact(t, filepath.Join(cache.RunDir(7), "v1.old"), 4, 10, now)
+	// being extracted or replaced right now
+	cacheArtifact(t, filepath.Join(cache.RunDir(7), stagingPrefix+"123"), 5, 10, now)
+	cacheArtifact(t, filepath.Join(cache.Dir, backupPrefix+"456", "1"), 6, 10, now)
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if !slices.Equal(ids, []int64{2, 1, 3, 4}) {
+		t.Fatalf("Entries() = %v, want least recently used first without staging and backups", ids)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		maxAge  time.Duration
+		keep    []string
+		want    []int64
+	}{
+		{"no limits", 0, 0, nil, []int64{1, 2, 3}},
+		{"too old", 0, 90 * time.Minute, nil, []int64{2, 3}},
+		{"too big", 2500, 0, nil, []int64{2, 3}},
+		{"much too big", 5, 0, nil, nil},
+		{"kept", 5, 0, []string{"1"}, []int64{1}},
+		{"kept run", 0, time.Minute, []string{"run-7"}, []int64{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &Cache{Dir: t.TempDir(), MaxSize: tt.maxSize, MaxAge: tt.maxAge}
+			cacheArtifact(t, cache.ArtifactDir(1), 1, 1000, now.Add(-2*time.Hour))
+			cacheArtifact(t, filepath.Join(cache.RunDir(7), "report"), 2, 1000, now.Add(-time.Hour))
+			cacheArtifact(t, cache.ArtifactDir(3), 3, 1000, now)
+
+			var keep []string
+			for _, path := range tt.keep {
+				keep = append(keep, filepath.Join(cache.Dir, path))
+			}
+			if err := cache.Evict(keep...); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := cache.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int64
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Fatalf("left %v, want %v", ids, tt.want)
+			}
+
+			if _, err := os.Stat(cache.RunDir(7)); slices.Contains(tt.want, 2) == os.IsNotExist(err) {
+				t.Errorf("run directory exists = %v, want it removed with its last artifact", err == nil)
+			}
+		})
+	}
+}
+
+func TestDefaultCacheDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the user's cache directory is only taken from XDG_CACHE_HOME on Linux")
+	}
+
+	tests := []struct {
+		name string
+		xdg  string
+		home string
+		want string
+	}{
+		{"xdg", "/var/cache/wile", "/home/wile", "/var/cache/wile/platui"},
+		{"home", "", "/home/wile", "/home/wile/.cache/platui"},
+		{"neither", "", "", filepath.Join(os.TempDir(), "platui")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", tt.xdg)
+			t.Setenv("HOME", tt.home)
+
+			if got := DefaultCacheDir(); got != tt.want {
+				t.Errorf("DefaultCacheDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
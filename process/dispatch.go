@@ -0,0 +1,173 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v62/github"
+	"gopkg.in/yaml.v3"
+)
+
+// Input is one of the on.workflow_dispatch.inputs of a workflow. Type is one
+// of string, choice, boolean, environment or number.
+type Input struct {
+	Name        string
+	Description string
+	Type        string
+	Required    bool
+	Default     string
+	Options     []string
+}
+
+var errNotDispatchable = errors.New("workflow has no workflow_dispatch trigger")
+
+func (p *Process) GetDefaultBranch(organization string, repository string) (string, error) {
+	repo, _, err := p.client.Repositories.Get(p.ctx, organization, repository)
+	if err != nil {
+		return "", classify("get repository", err)
+	}
+
+	return repo.GetDefaultBranch(), nil
+}
+
+// GetWorkflowInputs reads the workflow file at path as of ref.
+func (p *Process) GetWorkflowInputs(organization string, repository string, path string, ref string) ([]Input, error) {
+	file, _, _, err := p.client.Repositories.GetContents(p.ctx, organization, repository, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		return nil, classify("read workflow", err)
+	}
+	if file == nil {
+		return nil, &Error{Kind: NotFound, Op: "read workflow", Err: fmt.Errorf("%s is not a file", path)}
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, classify("read workflow", err)
+	}
+
+	inputs, err := parseWorkflowInputs([]byte(content))
+	if err != nil {
+		return nil, classify("read workflow inputs", err)
+	}
+
+	return inputs, nil
+}
+
+// GetEnvironments names the deployment environments of a repository, which
+// environment inputs choose from.
+func (p *Process) GetEnvironments(organization string, repository string) ([]string, error) {
+	var environments []string
+
+	opts := &github.EnvironmentListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		response, resp, err := p.client.Repositories.ListEnvironments(p.ctx, organization, repository, opts)
+		if err != nil {
+			return nil, classify("list environments", err)
+		}
+
+		for _, environment := range response.Environments {
+			environments = append(environments, environment.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return environments, nil
+}
+
+// DispatchWorkflow passes every input as a string, which GitHub converts to
+// the input's type.
+func (p *Process) DispatchWorkflow(organization string, repository string, workflowId int64, ref string, inputs map[string]string) error {
+	event := github.CreateWorkflowDispatchEventRequest{Ref: ref, Inputs: map[string]interface{}{}}
+	for name, value := range inputs {
+		event.Inputs[name] = value
+	}
+
+	_, err := p.client.Actions.CreateWorkflowDispatchEventByID(p.ctx, organization, repository, workflowId, event)
+	return classify("dispatch workflow", err)
+}
+
+func parseWorkflowInputs(content []byte) ([]Input, error) {
+	var workflow struct {
+		On yaml.Node `yaml:"on"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, err
+	}
+
+	// on: workflow_dispatch, on: [push, workflow_dispatch] or a mapping
+	on := workflow.On
+	switch on.Kind {
+	case yaml.ScalarNode:
+		if on.Value == "workflow_dispatch" {
+			return nil, nil
+		}
+	case yaml.SequenceNode:
+		for _, event := range on.Content {
+			if event.Value == "workflow_dispatch" {
+				return nil, nil
+			}
+		}
+	case yaml.MappingNode:
+		if dispatch := mappingValue(&on, "workflow_dispatch"); dispatch != nil {
+			return parseInputs(mappingValue(dispatch, "inputs"))
+		}
+	}
+
+	return nil, errNotDispatchable
+}
+
+// parseInputs keeps the inputs in the order they are declared in.
+func parseInputs(node *yaml.Node) ([]Input, error) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var inputs []Input
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var spec struct {
+			Description string    `yaml:"description"`
+			Required    bool      `yaml:"required"`
+			Default     yaml.Node `yaml:"default"`
+			Type        string    `yaml:"type"`
+			Options     []string  `yaml:"options"`
+		}
+		if err := node.Content[i+1].Decode(&spec); err != nil {
+			return nil, fmt.Errorf("input %s: %w", node.Content[i].Value, err)
+		}
+
+		input := Input{
+			Name:        node.Content[i].Value,
+			Description: strings.TrimSpace(spec.Description),
+			Type:        spec.Type,
+			Required:    spec.Required,
+			Default:     spec.Default.Value,
+			Options:     spec.Options,
+		}
+		if input.Type == "" {
+			input.Type = "string"
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	return inputs, nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package process
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseWorkflowInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Input
+		wantErr error
+	}{
+		{"scalar trigger", "on: workflow_dispatch\n", nil, nil},
+		{"list of triggers", "on: [push, workflow_dispatch]\n", nil, nil},
+		{"without inputs", "on:\n  push:\n  workflow_dispatch:\n", nil, nil},
+		{
+			"inputs in order",
+			`on:
+  workflow_dispatch:
+    inputs:
+      project:
+        description: "  Playwright project to run  "
+        type: choice
+        required: true
+        default: chromium
+        options: [chromium, firefox]
+      grep:
+        description: Only run tests matching
+      shards:
+        type: number
+        default: 2
+      debug:
+        type: boolean
+        default: false
+      target:
+        type: environment
+`,
+			[]Input{
+				{Name: "project", Description: "Playwright project to run", Type: "choice", Required: true, Default: "chromium", Options: []string{"chromium", "firefox"}},
+				{Name: "grep", Description: "Only run tests matching", Type: "string"},
+				{Name: "shards", Type: "number", Default: "2"},
+				{Name: "debug", Type: "boolean", Default: "false"},
+				{Name: "target", Type: "environment"},
+			},
+			nil,
+		},
+		{"push only", "on: push\n", nil, errNotDispatchable},
+		{"no triggers", "name: CI\n", nil, errNotDispatchable},
+		{"other triggers", "on:\n  push:\n    branches: [main]\n", nil, errNotDispatchable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWorkflowInputs([]byte(tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseWorkflowInputs() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseWorkflowInputs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWorkflowInputsInvalid(t *testing.T) {
+	for _, content := range []string{
+		"on: [push\n",
+		"on:\n  workflow_dispatch:\n    inputs:\n      project:\n        options: chromium\n",
+	} {
+		if _, err := parseWorkflowInputs([]byte(content)); err == nil {
+			t.Errorf("parseWorkflowInputs(%q) succeeded, want an error", content)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+
+	"github.com/google/go-github/v62/github"
+)
+
+type ErrorKind int
+
+const (
+	Unknown ErrorKind = iota
+	Auth
+	NotFound
+	RateLimited
+	Network
+	Extraction
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case Auth:
+		return "auth"
+	case NotFound:
+		return "not found"
+	case RateLimited:
+		return "rate limited"
+	case Network:
+		return "network"
+	case Extraction:
+		return "extraction"
+	}
+
+	return "unknown"
+}
+
+// Error is returned by every Backend call that fails. Op names the operation
+// that failed, e.g. "list repositories".
+type Error struct {
+	Kind ErrorKind
+	Op   string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of the first *Error in err's chain, or Unknown.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return Unknown
+}
+
+func classify(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var twoFactorErr *github.TwoFactorAuthError
+	var responseErr *github.ErrorResponse
+	var netErr net.Error
+
+	kind := Unknown
+	switch {
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseErr):
+		kind = RateLimited
+	case errors.As(err, &twoFactorErr):
+		kind = Auth
+	case errors.As(err, &responseErr) && responseErr.Response != nil:
+		kind = kindForStatus(responseErr.Response.StatusCode)
+	case errors.As(err, &netErr):
+		kind = Network
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, os.ErrNotExist):
+		kind = NotFound
+	}
+
+	return &Error{Kind: kind, Op: op, Err: err}
+}
+
+func statusError(op string, resp *http.Response) error {
+	kind := kindForStatus(resp.StatusCode)
+	// GitHub answers 403 rather than 429 when the rate limit is used up
+	if resp.StatusCode == http.StatusForbidden && (resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "") {
+		kind = RateLimited
+	}
+
+	return &Error{
+		Kind: kind,
+		Op:   op,
+		Err:  fmt.Errorf("unexpected status %s", resp.Status),
+	}
+}
+
+func kindForStatus(code int) ErrorKind {
+	switch {
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return Auth
+	case code == http.StatusNotFound, code == http.StatusGone:
+		return NotFound
+	case code == http.StatusTooManyRequests:
+		return RateLimited
+	case code >= 500:
+		return Network
+	}
+
+	return Unknown
+}
+
+func extractionError(op string, err error) error {
+	return &Error{Kind: Extraction, Op: op, Err: err}
+}
@@ -0,0 +1,129 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v62/github"
+)
+
+// response is what GitHub would answer with status, headers and body.
+func response(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	request, _ := http.NewRequest(http.MethodGet, "https://api.github.com/orgs/acme/repos", nil)
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    request,
+	}
+}
+
+var rateLimited = http.Header{
+	"X-Ratelimit-Limit":     {"5000"},
+	"X-Ratelimit-Remaining": {"0"},
+	"X-Ratelimit-Reset":     {"1717407000"},
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"unauthorized", github.CheckResponse(response(401, nil, `{"message":"Bad credentials"}`)), Auth},
+		{"forbidden", github.CheckResponse(response(403, nil, `{"message":"Resource not accessible by integration"}`)), Auth},
+		{"rate limit used up", github.CheckResponse(response(403, rateLimited, `{"message":"API rate limit exceeded"}`)), RateLimited},
+		{"secondary rate limit", github.CheckResponse(response(403, http.Header{"Retry-After": {"60"}}, `{"message":"You have exceeded a secondary rate limit","documentation_url":"https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits"}`)), RateLimited},
+		{"too many requests", github.CheckResponse(response(429, nil, `{}`)), RateLimited},
+		{"two factor", github.CheckResponse(response(401, http.Header{"X-Github-Otp": {"required; app"}}, `{}`)), Auth},
+		{"not found", github.CheckResponse(response(404, nil, `{"message":"Not Found"}`)), NotFound},
+		{"server error", github.CheckResponse(response(502, nil, `{}`)), Network},
+		{"unprocessable", github.CheckResponse(response(422, nil, `{"message":"Validation Failed"}`)), Unknown},
+		{"connection refused", &url.Error{Op: "Get", URL: "https://api.github.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}, Network},
+		{"no such host", &net.DNSError{Err: "no such host", Name: "api.github.com", IsNotFound: true}, Network},
+		{"no program to open files", &exec.Error{Name: "xdg-open", Err: exec.ErrNotFound}, NotFound},
+		{"already classified", extractionError("extract report", errors.New("too many files")), Extraction},
+		{"anything else", errors.New("unexpected EOF"), Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify("list repositories", tt.err)
+			if got := KindOf(err); got != tt.want {
+				t.Fatalf("KindOf(classify(%v)) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if err := classify("list repositories", nil); err != nil {
+		t.Errorf("classify(nil) = %v, want nil", err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		want   ErrorKind
+	}{
+		{"unauthorized", 401, nil, Auth},
+		{"expired signed URL", 403, nil, Auth},
+		{"rate limit used up", 403, rateLimited, RateLimited},
+		{"secondary rate limit", 403, http.Header{"Retry-After": {"60"}}, RateLimited},
+		{"not found", 404, nil, NotFound},
+		{"expired artifact", 410, nil, NotFound},
+		{"too many requests", 429, nil, RateLimited},
+		{"server error", 503, nil, Network},
+		{"bad request", 400, nil, Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := statusError("download artifact", response(tt.status, tt.header, ""))
+			if got := KindOf(err); got != tt.want {
+				t.Fatalf("KindOf(statusError(%d)) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorRoundTrip(t *testing.T) {
+	cause := github.CheckResponse(response(404, nil, `{"message":"Not Found"}`))
+	err := fmt.Errorf("loading workflows: %w", classify("list workflows", cause))
+
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("errors.As(%v) found no *Error", err)
+	}
+	if e.Kind != NotFound || e.Op != "list workflows" {
+		t.Errorf("Error = %v %q, want %v %q", e.Kind, e.Op, NotFound, "list workflows")
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("%v doesn't wrap %v", err, cause)
+	}
+	var responseErr *github.ErrorResponse
+	if !errors.As(err, &responseErr) || responseErr.Response.StatusCode != 404 {
+		t.Errorf("errors.As(%v) found no 404 response", err)
+	}
+	if !strings.HasPrefix(err.Error(), "loading workflows: list workflows: ") {
+		t.Errorf("Error() = %q, want the operation in front of the cause", err.Error())
+	}
+
+	// classifying again keeps the first operation
+	if again := classify("list runs", err); again != err || KindOf(again) != NotFound {
+		t.Errorf("classify() again = %v, want %v unchanged", again, err)
+	}
+}
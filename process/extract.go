@@ -0,0 +1,538 @@
+package process
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"cmp"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// limits protect against archives that unpack to far more than they weigh,
+// whether on purpose or not. Ratios are only checked on entries of more than
+// ratioThreshold bytes, small files of repeated text compress too well.
+// Archives are unpacked at most maxDepth levels below the artifact.
+type limits struct {
+	maxSize        int64
+	maxFiles       int
+	maxRatio       float64
+	ratioThreshold int64
+	maxDepth       int
+}
+
+var extractLimits = limits{
+	maxSize:        20 << 30,
+	maxFiles:       200_000,
+	maxRatio:       1000,
+	ratioThreshold: 1 << 20,
+	maxDepth:       3,
+}
+
+var (
+	errUnsafePath  = errors.New("path leaves the archive")
+	errUnsafeLink  = errors.New("symlink points outside the archive")
+	errTooLarge    = errors.New("archive unpacks to more than the size limit")
+	errTooMany     = errors.New("archive has more files than the limit")
+	errCompression = errors.New("entry is compressed suspiciously well")
+	errTrace       = errors.New("archive is a Playwright trace")
+)
+
+// budget is what is left of the limits while extracting.
+type budget struct {
+	limits
+	size  int64
+	files int
+}
+
+func newBudget(l limits) *budget {
+	return &budget{limits: l, size: l.maxSize, files: l.maxFiles}
+}
+
+// extractZip extracts archive into dir, which must exist. Symlinks are
+// created last, so nothing is ever written through one, and only when they
+// resolve to somewhere inside dir. Errors name the entry that failed.
+func extractZip(archive *zip.Reader, dir string, b *budget) error {
+	var dirs []dirTime
+	var links []link
+
+	for _, f := range archive.File {
+		if !filepath.IsLocal(f.Name) {
+			return extractionError("extract "+f.Name, errUnsafePath)
+		}
+		filePath := filepath.Join(dir, f.Name)
+
+		b.files--
+		if b.files < 0 {
+			return extractionError("extract "+f.Name, errTooMany)
+		}
+
+		switch mode := f.Mode(); {
+		case mode.IsDir():
+			if err := os.MkdirAll(filePath, 0755); err != nil {
+				return extractionError("extract "+f.Name, err)
+			}
+			dirs = append(dirs, dirTime{filePath, f.Modified})
+
+		case mode&os.ModeSymlink != 0:
+			target, err := readLink(f)
+			if err != nil {
+				return extractionError("extract "+f.Name, err)
+			}
+			links = append(links, link{f.Name, target})
+
+		case mode.IsRegular():
+			if err := extractFile(f, filePath, b, nil); err != nil {
+				return extractionError("extract "+f.Name, err)
+			}
+
+		default:
+			// devices, pipes and the like have no business in an artifact
+			continue
+		}
+	}
+
+	return finish(dir, dirs, links)
+}
+
+// extractTar is extractZip for tar archives, which can also hold hard links.
+// As entries aren't compressed one by one, the ratio is checked against the
+// compressed size of the whole archive.
+func extractTar(r io.Reader, compressed int64, dir string, b *budget) error {
+	var dirs []dirTime
+	var links []link
+	var written int64
+
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return extractionError("extract archive", err)
+		}
+
+		if !filepath.IsLocal(header.Name) {
+			return extractionError("extract "+header.Name, errUnsafePath)
+		}
+		filePath := filepath.Join(dir, header.Name)
+
+		b.files--
+		if b.files < 0 {
+			return extractionError("extract "+header.Name, errTooMany)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(filePath, 0755); err != nil {
+				return extractionError("extract "+header.Name, err)
+			}
+			dirs = append(dirs, dirTime{filePath, header.ModTime})
+
+		case tar.TypeSymlink:
+			links = append(links, link{header.Name, header.Linkname})
+
+		case tar.TypeLink:
+			if err := extractHardLink(header, dir); err != nil {
+				return extractionError("extract "+header.Name, err)
+			}
+
+		case tar.TypeReg:
+			n, err := writeFile(archive, filePath, header.FileInfo().Mode().Perm(), header.ModTime, b, b.ratioLimit(compressed)-written)
+			if err != nil {
+				return extractionError("extract "+header.Name, err)
+			}
+			written += n
+
+		default:
+			continue
+		}
+	}
+
+	return finish(dir, dirs, links)
+}
+
+type dirTime struct {
+	path     string
+	modified time.Time
+}
+
+type link struct {
+	name   string
+	target string
+}
+
+// finish creates the symlinks of an archive once its files are written and
+// restores the times of its directories, which writing the files changed.
+func finish(dir string, dirs []dirTime, links []link) error {
+	for _, l := range links {
+		if err := extractLink(l, dir); err != nil {
+			return extractionError("extract "+l.name, err)
+		}
+	}
+	if err := checkLinks(links, dir); err != nil {
+		return err
+	}
+
+	// deepest first, restoring a directory's time changes its parent's
+	slices.Reverse(dirs)
+	for _, d := range dirs {
+		os.Chtimes(d.path, d.modified, d.modified)
+	}
+
+	return nil
+}
+
+// extractFile reports progress in bytes of the extracted file when given.
+func extractFile(f *zip.File, filePath string, b *budget, progress ProgressFunc) error {
+	fileInArchive, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer fileInArchive.Close()
+
+	var r io.Reader = fileInArchive
+	if progress != nil {
+		r = io.TeeReader(r, &progressWriter{total: int64(min(f.UncompressedSize64, math.MaxInt64)), progress: progress})
+	}
+
+	_, err = writeFile(r, filePath, f.Mode().Perm(), f.Modified, b, b.ratioLimit(int64(min(f.CompressedSize64, math.MaxInt64))))
+	return err
+}
+
+// ratioLimit is the most that compressed bytes may unpack to before their
+// ratio is suspicious. It never exceeds maxSize, which is checked anyway.
+func (b *budget) ratioLimit(compressed int64) int64 {
+	limit := b.maxRatio * float64(max(compressed, 1))
+	if limit >= float64(b.maxSize) {
+		return b.maxSize
+	}
+
+	return max(b.ratioThreshold, int64(limit))
+}
+
+// writeFile copies r to filePath and takes what it wrote from the budget. It
+// stops as soon as more than the budget or ratioLimit bytes come out, so a
+// decompression bomb is never written out in full.
+func writeFile(r io.Reader, filePath string, perm os.FileMode, modified time.Time, b *budget, ratioLimit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return 0, err
+	}
+
+	dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm|0600)
+	if err != nil {
+		return 0, err
+	}
+	defer dstFile.Close()
+
+	// the sizes in the header can't be trusted, count what comes out
+	written, err := io.Copy(dstFile, io.LimitReader(r, max(min(b.size, ratioLimit), 0)+1))
+	if err != nil {
+		return written, err
+	}
+	b.size -= written
+	if b.size < 0 {
+		return written, errTooLarge
+	}
+	if written > ratioLimit {
+		return written, errCompression
+	}
+
+	if err := dstFile.Close(); err != nil {
+		return written, err
+	}
+
+	return written, os.Chtimes(filePath, modified, modified)
+}
+
+func readLink(f *zip.File) (string, error) {
+	r, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	target, err := io.ReadAll(io.LimitReader(r, 4096))
+	if err != nil {
+		return "", err
+	}
+
+	return string(target), nil
+}
+
+// extractLink only creates relative symlinks that stay inside dir, both as
+// far as their path goes and once resolved through the symlinks created
+// before them. Nothing is created or removed through a symlink: a link whose
+// parent path goes through one is refused before touching the disk.
+func extractLink(l link, dir string) error {
+	linkPath := filepath.Join(dir, l.name)
+	resolved := filepath.Join(filepath.Dir(linkPath), l.target)
+	if filepath.IsAbs(l.target) || !within(dir, resolved) {
+		return errUnsafeLink
+	}
+
+	if through, err := throughLink(dir, filepath.Dir(linkPath)); err != nil || through {
+		return cmp.Or(err, errUnsafeLink)
+	}
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(linkPath); err == nil && !info.IsDir() {
+		os.Remove(linkPath)
+	}
+
+	if err := os.Symlink(l.target, linkPath); err != nil {
+		return err
+	}
+	if !resolvesWithin(dir, linkPath) {
+		os.Remove(linkPath)
+		return errUnsafeLink
+	}
+
+	return nil
+}
+
+// throughLink reports whether the path from dir down to path goes through a
+// symlink. Parts of the path that don't exist yet can't be one.
+func throughLink(dir string, path string) (bool, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || !filepath.IsLocal(rel) && rel != "." {
+		return true, err
+	}
+
+	current := dir
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		if part == "." {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// resolvesWithin reports whether path stays inside dir once its symlinks are
+// resolved. Dangling links stay inside, there is nothing to read through them.
+func resolvesWithin(dir string, path string) bool {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return errors.Is(err, fs.ErrNotExist)
+	}
+
+	return within(root, resolved)
+}
+
+// extractHardLink links to a regular file extracted before it. Symlinks
+// aren't created yet, so the target can't be one.
+func extractHardLink(header *tar.Header, dir string) error {
+	if !filepath.IsLocal(header.Linkname) {
+		return errUnsafeLink
+	}
+
+	target := filepath.Join(dir, header.Linkname)
+	info, err := os.Lstat(target)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return errUnsafeLink
+	}
+
+	linkPath := filepath.Join(dir, header.Name)
+	for _, path := range []string{filepath.Dir(target), filepath.Dir(linkPath)} {
+		if through, err := throughLink(dir, path); err != nil || through {
+			return cmp.Or(err, errUnsafeLink)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(linkPath); err == nil && !info.IsDir() {
+		os.Remove(linkPath)
+	}
+
+	return os.Link(target, linkPath)
+}
+
+// checkLinks catches symlinks that only leave dir through symlinks created
+// after them, which extractLink can't tell yet.
+func checkLinks(links []link, dir string) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	for _, l := range links {
+		if !resolvesWithin(dir, filepath.Join(dir, l.name)) {
+			return extractionError("extract "+l.name, errUnsafeLink)
+		}
+	}
+
+	return nil
+}
+
+func within(dir string, path string) bool {
+	dir = filepath.Clean(dir)
+	path = filepath.Clean(path)
+
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
+// archiveExtensions are the archives unpackNested unpacks, longest first.
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar", ".zip"}
+
+// isTrace tells Playwright traces apart, show-trace opens them as they are.
+// The HTML report keeps its traces as data/<sha1>.zip, so besides the name
+// the archive is checked for the trace itself.
+func isTrace(name string, archive *zip.Reader) bool {
+	if strings.EqualFold(filepath.Base(name), "trace.zip") {
+		return true
+	}
+
+	for _, f := range archive.File {
+		if !strings.Contains(f.Name, "/") && strings.HasSuffix(f.Name, ".trace") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func archiveExtension(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) && len(name) > len(ext) {
+			return ext
+		}
+	}
+
+	return ""
+}
+
+// unpackNested replaces the archives found in dir by a directory of the same
+// name without the extension, and unpacks the archives found in those until
+// maxDepth. Playwright traces, archives that can't be read and archives whose
+// directory name is taken are left packed. Everything unpacked counts towards b.
+func unpackNested(dir string, b *budget, depth int) error {
+	if depth > b.maxDepth {
+		return nil
+	}
+
+	var archives []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && archiveExtension(d.Name()) != "" {
+			archives = append(archives, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return extractionError("unpack archives", err)
+	}
+
+	for _, archive := range archives {
+		name, _ := filepath.Rel(dir, archive)
+		target := archive[:len(archive)-len(archiveExtension(archive))]
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		}
+		if err := os.Mkdir(target, 0755); err != nil {
+			return extractionError("unpack "+name, err)
+		}
+
+		err := unpack(archive, target, b)
+		if errors.Is(err, errTrace) || malformed(err) {
+			os.RemoveAll(target)
+			continue
+		}
+		if err != nil {
+			return extractionError("unpack "+name, err)
+		}
+
+		if err := os.Remove(archive); err != nil {
+			return extractionError("unpack "+name, err)
+		}
+		if err := unpackNested(target, b, depth+1); err != nil {
+			return extractionError("unpack "+name, err)
+		}
+	}
+
+	return nil
+}
+
+func unpack(archive string, dir string, b *budget) error {
+	if archiveExtension(archive) == ".zip" {
+		r, err := zip.OpenReader(archive)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		if isTrace(archive, &r.Reader) {
+			return errTrace
+		}
+
+		return extractZip(&r.Reader, dir, b)
+	}
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = file
+	if ext := archiveExtension(archive); ext == ".tar.gz" || ext == ".tgz" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return extractTar(r, info.Size(), dir, b)
+}
+
+// malformed tells archives that are broken, or just named like one, from
+// archives that break the limits.
+func malformed(err error) bool {
+	var corrupt flate.CorruptInputError
+	return errors.Is(err, zip.ErrFormat) ||
+		errors.Is(err, zip.ErrAlgorithm) ||
+		errors.Is(err, zip.ErrChecksum) ||
+		errors.Is(err, gzip.ErrHeader) ||
+		errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, tar.ErrHeader) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &corrupt)
+}
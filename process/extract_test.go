@@ -0,0 +1,343 @@
+package process
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// entry is a file, directory or link of an archive built for a test. Links
+// are symlinks unless hard is set.
+type entry struct {
+	name     string
+	content  string
+	dir      bool
+	link     string
+	hard     bool
+	modified time.Time
+}
+
+func buildZip(t *testing.T, entries []entry) *zip.Reader {
+	t.Helper()
+
+	content := zipBytes(t, entries)
+	r, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func zipBytes(t *testing.T, entries []entry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: e.modified}
+		switch {
+		case e.dir:
+			header.Name += "/"
+			header.SetMode(os.ModeDir | 0755)
+		case e.link != "":
+			header.SetMode(os.ModeSymlink | 0777)
+		default:
+			header.SetMode(0644)
+		}
+
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := e.content
+		if e.link != "" {
+			content = e.link
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func buildTar(t *testing.T, entries []entry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, ModTime: e.modified, Size: int64(len(e.content))}
+		switch {
+		case e.dir:
+			header.Typeflag, header.Mode, header.Size = tar.TypeDir, 0755, 0
+		case e.hard:
+			header.Typeflag, header.Linkname, header.Size = tar.TypeLink, e.link, 0
+		case e.link != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, e.link, 0
+		default:
+			header.Typeflag = tar.TypeReg
+		}
+
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := w.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// stagingDir is nested a few levels deep, so links climbing out of it land
+// in a directory the test owns.
+func stagingDir(t *testing.T) (base string, staging string) {
+	base = t.TempDir()
+	staging = filepath.Join(base, "a", "b", "staging")
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return base, staging
+}
+
+func TestExtractZipRefusesEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    error
+	}{
+		{"parent path", []entry{{name: "../../../victim", content: "pwned"}}, errUnsafePath},
+		{"absolute path", []entry{{name: "/victim", content: "pwned"}}, errUnsafePath},
+		{"absolute link", []entry{{name: "l", link: "/etc"}}, errUnsafeLink},
+		{"link to parent", []entry{{name: "l", link: "../../.."}}, errUnsafeLink},
+		{
+			// s/s/s is the staging directory itself, which the path alone doesn't tell
+			"link through link",
+			[]entry{{name: "s", link: "."}, {name: "l", link: "s/s/s/../../.."}},
+			errUnsafeLink,
+		},
+		{
+			"link inside link",
+			[]entry{{name: "s", link: "."}, {name: "l", link: "s/s/s/../../.."}, {name: "l/victim", link: "."}},
+			errUnsafeLink,
+		},
+		{
+			"link chained later",
+			[]entry{{name: "l", link: "d/.."}, {name: "d", link: "s/s/.."}, {name: "s", link: "."}},
+			errUnsafeLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, staging := stagingDir(t)
+			victim := filepath.Join(base, "victim")
+			if err := os.WriteFile(victim, []byte("safe"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractZip(buildZip(t, tt.entries), staging, newBudget(extractLimits))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("extractZip() = %v, want %v", err, tt.want)
+			}
+
+			info, err := os.Lstat(victim)
+			if err != nil || !info.Mode().IsRegular() {
+				t.Fatalf("victim outside the staging directory was replaced: %v %v", info, err)
+			}
+			if content, _ := os.ReadFile(victim); string(content) != "safe" {
+				t.Fatalf("victim = %q, want it untouched", content)
+			}
+		})
+	}
+}
+
+func TestExtractZipKeepsLinksInside(t *testing.T) {
+	_, staging := stagingDir(t)
+	entries := []entry{
+		{name: "report/index.html", content: "<html>"},
+		{name: "latest", link: "report"},
+		{name: "report/self", link: "../report/index.html"},
+	}
+
+	if err := extractZip(buildZip(t, entries), staging, newBudget(extractLimits)); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(staging, "latest", "self"))
+	if err != nil || string(content) != "<html>" {
+		t.Fatalf("latest/self = %q, %v", content, err)
+	}
+}
+
+func TestExtractTarHardLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+		want    error
+	}{
+		{"to earlier file", []entry{{name: "a.txt", content: "a"}, {name: "b.txt", link: "a.txt", hard: true}}, nil},
+		{"to parent", []entry{{name: "b.txt", link: "../victim", hard: true}}, errUnsafeLink},
+		{"to absolute path", []entry{{name: "b.txt", link: "/etc/passwd", hard: true}}, errUnsafeLink},
+		{"to directory", []entry{{name: "d", dir: true}, {name: "b", link: "d", hard: true}}, errUnsafeLink},
+		{"to missing file", []entry{{name: "b.txt", link: "a.txt", hard: true}}, os.ErrNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, staging := stagingDir(t)
+			archive := buildTar(t, tt.entries)
+
+			err := extractTar(bytes.NewReader(archive), int64(len(archive)), staging, newBudget(extractLimits))
+			if tt.want == nil && err != nil || !errors.Is(err, tt.want) {
+				t.Fatalf("extractTar() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+
+			content, err := os.ReadFile(filepath.Join(staging, "b.txt"))
+			if err != nil || string(content) != "a" {
+				t.Fatalf("b.txt = %q, %v", content, err)
+			}
+		})
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	small := limits{maxSize: 1 << 20, maxFiles: 10, maxRatio: 10, ratioThreshold: 1 << 10, maxDepth: 1}
+	zeros := strings.Repeat("\x00", 1<<20)
+
+	tests := []struct {
+		name    string
+		limits  limits
+		entries []entry
+		want    error
+		// written is the most that may end up on disk
+		written int64
+	}{
+		{
+			"size",
+			limits{maxSize: 100, maxFiles: 10, maxRatio: 1e9, ratioThreshold: 1 << 30},
+			[]entry{{name: "a", content: strings.Repeat("x", 60)}, {name: "b", content: strings.Repeat("y", 60)}},
+			errTooLarge,
+			101,
+		},
+		{
+			"count",
+			limits{maxSize: 1 << 20, maxFiles: 2, maxRatio: 1e9, ratioThreshold: 1 << 30},
+			[]entry{{name: "a"}, {name: "b"}, {name: "c"}},
+			errTooMany,
+			0,
+		},
+		{
+			// a megabyte of zeros deflates to about a kilobyte
+			"ratio",
+			small,
+			[]entry{{name: "bomb", content: zeros}},
+			errCompression,
+			64 << 10,
+		},
+		{
+			"small files aren't checked for their ratio",
+			small,
+			[]entry{{name: "small", content: strings.Repeat("\x00", 1<<10)}},
+			nil,
+			1 << 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, staging := stagingDir(t)
+
+			err := extractZip(buildZip(t, tt.entries), staging, newBudget(tt.limits))
+			if tt.want == nil && err != nil || !errors.Is(err, tt.want) {
+				t.Fatalf("extractZip() = %v, want %v", err, tt.want)
+			}
+
+			var written int64
+			filepath.WalkDir(staging, func(path string, d os.DirEntry, err error) error {
+				if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+					written += info.Size()
+				}
+				return nil
+			})
+			if written > tt.written {
+				t.Fatalf("wrote %d bytes, want at most %d", written, tt.written)
+			}
+		})
+	}
+}
+
+func TestExtractTarRatio(t *testing.T) {
+	_, staging := stagingDir(t)
+	l := limits{maxSize: 1 << 30, maxFiles: 10, maxRatio: 2, ratioThreshold: 1 << 10}
+	archive := buildTar(t, []entry{{name: "a", content: strings.Repeat("x", 4<<10)}})
+
+	// as if the tar had been gzipped down to a kilobyte
+	err := extractTar(bytes.NewReader(archive), 1<<10, staging, newBudget(l))
+	if !errors.Is(err, errCompression) {
+		t.Fatalf("extractTar() = %v, want %v", err, errCompression)
+	}
+	if info, err := os.Stat(filepath.Join(staging, "a")); err == nil && info.Size() > 2<<10+1 {
+		t.Fatalf("wrote %d bytes, want at most %d", info.Size(), 2<<10+1)
+	}
+}
+
+func TestExtractKeepsTimes(t *testing.T) {
+	modified := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	entries := []entry{
+		{name: "report", dir: true, modified: modified.Add(-time.Hour)},
+		{name: "report/index.html", content: "<html>", modified: modified},
+	}
+
+	t.Run("zip", func(t *testing.T) {
+		_, staging := stagingDir(t)
+		if err := extractZip(buildZip(t, entries), staging, newBudget(extractLimits)); err != nil {
+			t.Fatal(err)
+		}
+		checkTimes(t, staging, modified)
+	})
+
+	t.Run("tar", func(t *testing.T) {
+		_, staging := stagingDir(t)
+		archive := buildTar(t, entries)
+		if err := extractTar(bytes.NewReader(archive), int64(len(archive)), staging, newBudget(extractLimits)); err != nil {
+			t.Fatal(err)
+		}
+		checkTimes(t, staging, modified)
+	})
+}
+
+func checkTimes(t *testing.T, staging string, modified time.Time) {
+	t.Helper()
+
+	for name, want := range map[string]time.Time{
+		"report":            modified.Add(-time.Hour),
+		"report/index.html": modified,
+	} {
+		info, err := os.Stat(filepath.Join(staging, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(want) {
+			t.Errorf("%s modified %v, want %v", name, info.ModTime(), want)
+		}
+	}
+}
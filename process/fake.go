@@ -0,0 +1,542 @@
+package process
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake is an in-memory Backend used for tests and demos. The first of the
+// Organizations is the user's own account. Repositories are keyed by
+// organization, workflows, environments and workflow runs by
+// "organization/repository", dispatch inputs by workflow ID, jobs and
+// artifacts by workflow run ID and logs by job ID. Artifacts are downloaded
+// to Cache, a directory in the temporary directory unless set.
+type Fake struct {
+	Organizations []Result
+	Repositories  map[string][]Result
+	Workflows     map[string][]Result
+	Inputs        map[int64][]Input
+	Environments  map[string][]string
+	WorkflowRuns  map[string][]Run
+	Jobs          map[int64][]Job
+	Logs          map[int64]string
+	Artifacts     map[int64][]Artifact
+	Files         map[int64]map[string]string
+	Cache         *Cache
+
+	// PerPage splits repositories and workflow runs into pages when set.
+	PerPage int
+
+	// Err is returned from every call when set.
+	Err error
+
+	// NoRanges makes inspecting artifacts fail as if GitHub didn't support
+	// range requests.
+	NoRanges bool
+
+	mu         sync.Mutex
+	Downloaded []int64
+	Opened     []string
+	Searches   []string
+	// Actions records reruns and cancellations, e.g. "rerun 100".
+	Actions []string
+}
+
+var _ Backend = (*Fake)(nil)
+
+var demoStart = time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC)
+
+const demoLog = "\ufeff2024-06-03T09:30:00.0000000Z ##[group]Runner Image\n" +
+	"2024-06-03T09:30:00.0000000Z Image: ubuntu-22.04\n" +
+	"2024-06-03T09:30:00.0000000Z ##[endgroup]\n" +
+	"2024-06-03T09:30:02.0000000Z ##[group]Run npx playwright test\n" +
+	"2024-06-03T09:30:02.0000000Z \x1b[36;1mnpx playwright test\x1b[0m\n" +
+	"2024-06-03T09:30:02.0000000Z ##[endgroup]\n" +
+	"2024-06-03T09:33:40.0000000Z Running 42 tests using 2 workers\n" +
+	"2024-06-03T09:33:50.0000000Z \x1b[31m  1) [chromium] › login.spec.ts:12:5 › redirects after login\x1b[39m\n" +
+	"2024-06-03T09:33:50.0000000Z ##[warning]1 flaky test\n" +
+	"2024-06-03T09:34:00.0000000Z ##[error]Process completed with exit code 1.\n"
+
+func NewFake() *Fake {
+	return &Fake{
+		Organizations: []Result{
+			{Name: "wile", Title: "your own and collaborator repositories"},
+			{ID: 1, Name: "acme"},
+			{ID: 2, Name: "globex"},
+		},
+		Repositories: map[string][]Result{
+			"acme": {
+				{ID: 12, Name: "admin", Branch: "master", Visibility: "internal", PushedAt: demoStart.Add(-400 * 24 * time.Hour), Archived: true},
+				{ID: 11, Name: "api", Branch: "main", Visibility: "private", PushedAt: demoStart.Add(-3 * 24 * time.Hour)},
+				{ID: 10, Name: "web", Branch: "main", Visibility: "private", PushedAt: demoStart.Add(-time.Hour)},
+			},
+			"globex": {
+				{ID: 20, Name: "platform", Branch: "main", Visibility: "internal", PushedAt: demoStart.Add(-20 * 24 * time.Hour)},
+			},
+			"wile": {
+				{ID: 30, Name: "dotfiles", Owner: "wile", Branch: "main", Visibility: "public", PushedAt: demoStart.Add(-45 * 24 * time.Hour)},
+				{ID: 31, Name: "web", Owner: "acme", Title: "acme/web", Branch: "main", Visibility: "private", PushedAt: demoStart.Add(-time.Hour)},
+			},
+		},
+		Workflows: map[string][]Result{
+			"acme/web": {
+				{ID: 50, Name: "Playwright", Path: ".github/workflows/playwright.yml", State: "active"},
+				{ID: 51, Name: "Lint", Path: ".github/workflows/lint.yml", State: "active"},
+			},
+			"acme/api": {
+				{ID: 60, Name: "CI", Path: ".github/workflows/ci.yml", State: "active"},
+			},
+			"globex/platform": {
+				{ID: 70, Name: "Deploy", Path: ".github/workflows/deploy.yml", State: "disabled_manually"},
+			},
+		},
+		Inputs: map[int64][]Input{
+			50: {
+				{Name: "project", Description: "Playwright project to run", Type: "choice", Required: true, Default: "chromium", Options: []string{"chromium", "firefox", "webkit"}},
+				{Name: "grep", Description: "Only run tests matching", Type: "string"},
+				{Name: "shards", Type: "number", Default: "2"},
+				{Name: "debug", Type: "boolean", Default: "false"},
+				{Name: "environment", Description: "Where to run the tests against", Type: "environment", Default: "staging"},
+			},
+		},
+		Environments: map[string][]string{
+			"acme/web": {"production", "staging"},
+		},
+		WorkflowRuns: map[string][]Run{
+			"acme/web": {
+				{ID: 100, RunNumber: 412, Name: "Playwright", Title: "Fix login redirect", Status: "completed", Conclusion: "failure", WorkflowID: 50, Branch: "fix-login", Event: "pull_request", Actor: "wile", Attempt: 2, StartedAt: demoStart, CompletedAt: demoStart.Add(4 * time.Minute)},
+				{ID: 101, RunNumber: 411, Name: "Playwright", Title: "Bump dependencies", Status: "completed", Conclusion: "success", WorkflowID: 50, Branch: "main", Event: "push", Actor: "dependabot[bot]", Attempt: 1, StartedAt: demoStart.Add(-time.Hour), CompletedAt: demoStart.Add(-57 * time.Minute)},
+				{ID: 102, RunNumber: 230, Name: "Lint", Title: "Bump dependencies", Status: "completed", Conclusion: "cancelled", WorkflowID: 51, Branch: "main", Event: "push", Actor: "dependabot[bot]", Attempt: 1, StartedAt: demoStart.Add(-time.Hour), CompletedAt: demoStart.Add(-59 * time.Minute)},
+				{ID: 103, RunNumber: 413, Name: "Playwright", Title: "Add checkout flow", Status: "in_progress", WorkflowID: 50, Branch: "checkout", Event: "workflow_dispatch", Actor: "roadrunner", Attempt: 1, StartedAt: demoStart},
+			},
+			"acme/api": {
+				{ID: 110, RunNumber: 87, Name: "CI", Title: "Add health check", Status: "completed", Conclusion: "success", WorkflowID: 60, Branch: "main", Event: "push", Actor: "wile", Attempt: 1, StartedAt: demoStart, CompletedAt: demoStart.Add(90 * time.Second)},
+			},
+			"globex/platform": {
+				{ID: 200, RunNumber: 14, Name: "Deploy", Title: "Release 1.4.0", Status: "completed", Conclusion: "skipped", WorkflowID: 70, Branch: "v1.4.0", Event: "release", Actor: "hank", Attempt: 1, StartedAt: demoStart, CompletedAt: demoStart.Add(5 * time.Second)},
+			},
+		},
+		Jobs: map[int64][]Job{
+			100: {
+				{
+					ID: 5000, Name: "test (1/2)", Status: "completed", Conclusion: "failure", Runner: "GitHub Actions 2", Attempt: 1,
+					StartedAt: demoStart, CompletedAt: demoStart.Add(4 * time.Minute),
+					Steps: []Step{
+						{Number: 1, Name: "Set up job", Status: "completed", Conclusion: "success", StartedAt: demoStart, CompletedAt: demoStart.Add(2 * time.Second)},
+						{Number: 2, Name: "Run tests", Status: "completed", Conclusion: "failure", StartedAt: demoStart.Add(2 * time.Second), CompletedAt: demoStart.Add(4 * time.Minute)},
+					},
+				},
+				{
+					ID: 5001, Name: "test (2/2)", Status: "completed", Conclusion: "success", Runner: "GitHub Actions 3", Attempt: 1,
+					StartedAt: demoStart, CompletedAt: demoStart.Add(3 * time.Minute),
+					Steps: []Step{
+						{Number: 1, Name: "Set up job", Status: "completed", Conclusion: "success", StartedAt: demoStart, CompletedAt: demoStart.Add(2 * time.Second)},
+						{Number: 2, Name: "Run tests", Status: "completed", Conclusion: "success", StartedAt: demoStart.Add(2 * time.Second), CompletedAt: demoStart.Add(3 * time.Minute)},
+					},
+				},
+			},
+			103: {
+				{ID: 5002, Name: "test", Status: "in_progress", Runner: "GitHub Actions 4", Attempt: 1, StartedAt: demoStart},
+			},
+		},
+		Logs: map[int64]string{
+			5000: demoLog,
+			5001: demoLog,
+			5002: demoLog,
+		},
+		Artifacts: map[int64][]Artifact{
+			100: {
+				{ID: 1000, Name: "playwright-report-1", Size: 18_734_112, CreatedAt: demoStart.Add(4 * time.Minute), ExpiresAt: demoStart.Add(90 * 24 * time.Hour)},
+				{ID: 1001, Name: "playwright-report-2", Size: 9_212_004, CreatedAt: demoStart.Add(4 * time.Minute), ExpiresAt: demoStart.Add(90 * 24 * time.Hour)},
+				{ID: 1002, Name: "coverage", Size: 412_330, CreatedAt: demoStart.Add(-100 * 24 * time.Hour), ExpiresAt: demoStart.Add(-10 * 24 * time.Hour), Expired: true},
+			},
+			101: {
+				{ID: 1010, Name: "playwright-report", Size: 17_004_871, CreatedAt: demoStart.Add(-57 * time.Minute), ExpiresAt: demoStart.Add(90 * 24 * time.Hour)},
+			},
+		},
+		Files: map[int64]map[string]string{
+			1000: {"README.txt": "fake artifact 1000\n", "videos/checkout.webm": "fake video\n", "screenshots/checkout.png": "fake screenshot\n"},
+			1001: {"README.txt": "fake artifact 1001\n"},
+			1010: {"README.txt": "fake artifact 1010\n"},
+		},
+		Cache: &Cache{Dir: filepath.Join(os.TempDir(), "platui-fake")},
+	}
+}
+
+func (f *Fake) GetOrganizations() ([]Result, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return f.Organizations, nil
+}
+
+func (f *Fake) GetRepositories(organization string, page int) ([]Result, int, error) {
+	if f.Err != nil {
+		return nil, 0, f.Err
+	}
+
+	items, next := pageOf(f.Repositories[organization], page, f.PerPage)
+	return items, next, nil
+}
+
+// SearchRepositories matches query anywhere in the name, ignoring case, and
+// like GitHub returns ErrNoSearch for the user's own account.
+func (f *Fake) SearchRepositories(organization string, query string) ([]Result, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if len(f.Organizations) > 0 && organization == f.Organizations[0].Name {
+		return nil, ErrNoSearch
+	}
+
+	f.mu.Lock()
+	f.Searches = append(f.Searches, query)
+	f.mu.Unlock()
+
+	var found []Result
+	for _, repository := range f.Repositories[organization] {
+		if strings.Contains(strings.ToLower(repository.Name), strings.ToLower(query)) {
+			found = append(found, repository)
+		}
+	}
+
+	return found, nil
+}
+
+// pageOf returns a page of items when perPage is set and the next page, 0
+// after the last.
+func pageOf[T any](items []T, page int, perPage int) ([]T, int) {
+	if perPage == 0 {
+		return items, 0
+	}
+
+	page = max(page, 1)
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	next := 0
+	if end < len(items) {
+		next = page + 1
+	}
+
+	return items[start:end], next
+}
+
+func (f *Fake) GetWorkflows(organization string, repository string) ([]Result, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return f.Workflows[organization+"/"+repository], nil
+}
+
+func (f *Fake) GetDefaultBranch(organization string, repository string) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+
+	return "main", nil
+}
+
+// GetWorkflowInputs looks the workflow up by path and ignores ref.
+func (f *Fake) GetWorkflowInputs(organization string, repository string, path string, ref string) ([]Input, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	for _, workflow := range f.Workflows[organization+"/"+repository] {
+		if workflow.Path == path {
+			return f.Inputs[workflow.ID], nil
+		}
+	}
+
+	return nil, &Error{Kind: NotFound, Op: "read workflow", Err: fmt.Errorf("%s does not exist", path)}
+}
+
+func (f *Fake) GetEnvironments(organization string, repository string) ([]string, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return f.Environments[organization+"/"+repository], nil
+}
+
+func (f *Fake) DispatchWorkflow(organization string, repository string, workflowId int64, ref string, inputs map[string]string) error {
+	return f.record(fmt.Sprintf("dispatch %d on %s with %v", workflowId, ref, inputs))
+}
+
+// GetWorkflowRuns only honors the Status part of filter.
+func (f *Fake) GetWorkflowRuns(organization string, repository string, workflowId int64, filter RunFilter, page int) ([]Run, int, error) {
+	if f.Err != nil {
+		return nil, 0, f.Err
+	}
+
+	var runs []Run
+	for _, run := range f.WorkflowRuns[organization+"/"+repository] {
+		if workflowId != 0 && run.WorkflowID != workflowId {
+			continue
+		}
+		if filter.Status == "" || filter.Status == run.Status || filter.Status == run.Conclusion {
+			runs = append(runs, run)
+		}
+	}
+	runs, next := pageOf(runs, page, f.PerPage)
+	return runs, next, nil
+}
+
+func (f *Fake) RerunWorkflowRun(organization string, repository string, runId int64) error {
+	return f.record(fmt.Sprintf("rerun %d", runId))
+}
+
+func (f *Fake) RerunFailedJobs(organization string, repository string, runId int64) error {
+	return f.record(fmt.Sprintf("rerun failed %d", runId))
+}
+
+func (f *Fake) CancelWorkflowRun(organization string, repository string, runId int64) error {
+	return f.record(fmt.Sprintf("cancel %d", runId))
+}
+
+func (f *Fake) RerunJob(organization string, repository string, jobId int64) error {
+	return f.record(fmt.Sprintf("rerun job %d", jobId))
+}
+
+func (f *Fake) record(action string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	f.mu.Lock()
+	f.Actions = append(f.Actions, action)
+	f.mu.Unlock()
+
+	return nil
+}
+
+func (f *Fake) GetJobs(organization string, repository string, runId int64) ([]Job, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return f.Jobs[runId], nil
+}
+
+func (f *Fake) GetJob(organization string, repository string, jobId int64) (Job, error) {
+	if f.Err != nil {
+		return Job{}, f.Err
+	}
+
+	for _, jobs := range f.Jobs {
+		for _, job := range jobs {
+			if job.ID == jobId {
+				return job, nil
+			}
+		}
+	}
+
+	return Job{}, &Error{Kind: NotFound, Op: "get job", Err: fmt.Errorf("job %d does not exist", jobId)}
+}
+
+func (f *Fake) GetJobLogs(organization string, repository string, jobId int64, offset int64) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+
+	logs := f.Logs[jobId]
+	return logs[min(offset, int64(len(logs))):], nil
+}
+
+func (f *Fake) GetArtifacts(organization string, repository string, workflowId int64) ([]Artifact, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	return f.Artifacts[workflowId], nil
+}
+
+// DownloadArtifact writes the artifact's Files to Cache.ArtifactDir so the
+// filepicker has something to show.
+func (f *Fake) DownloadArtifact(ctx context.Context, organization string, repository string, artifact Artifact, force bool, progress ProgressFunc) error {
+	dst := f.Cache.ArtifactDir(artifact.ID)
+	if err := f.downloadArtifact(ctx, artifact, dst, force, progress); err != nil {
+		return err
+	}
+
+	f.Cache.Evict(dst)
+	return nil
+}
+
+// InspectArtifact lists the artifact's Files from a zip archive built in
+// memory.
+func (f *Fake) InspectArtifact(ctx context.Context, organization string, repository string, artifact Artifact) (Archive, error) {
+	if err := ctx.Err(); err != nil {
+		return Archive{}, err
+	}
+	archive, err := f.archive(artifact)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	return Archive{Path: artifact.Name + ".zip", Artifact: artifact, Entries: archiveEntries(archive)}, nil
+}
+
+func (f *Fake) ExtractArtifactEntry(ctx context.Context, organization string, repository string, artifact Artifact, entry ArchiveEntry, progress ProgressFunc) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	archive, err := f.archive(artifact)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := remoteEntryDir(artifact)
+	if err != nil {
+		return "", extractionError("extract "+entry.Path, err)
+	}
+
+	return extractEntry(archive, entry, dir, progress)
+}
+
+func (f *Fake) archive(artifact Artifact) (*zip.Reader, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoRanges {
+		return nil, ErrNoRanges
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range f.Files[artifact.ID] {
+		file, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: artifact.CreatedAt})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// DownloadArtifacts downloads one artifact after the other.
+func (f *Fake) DownloadArtifacts(ctx context.Context, organization string, repository string, runId int64, artifacts []Artifact, force bool, progress ArtifactProgressFunc) error {
+	dsts := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		dst, err := artifactDir(f.Cache.RunDir(runId), artifact)
+		if err != nil {
+			return err
+		}
+		dsts[i] = dst
+	}
+
+	for i, artifact := range artifacts {
+		err := f.downloadArtifact(ctx, artifact, dsts[i], force, func(done int64, total int64) {
+			if progress != nil {
+				progress(artifact.ID, done, total)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	f.Cache.Evict(f.Cache.RunDir(runId))
+	return nil
+}
+
+// downloadArtifact reports progress file by file and, like Process, only
+// replaces dst once every file was written and skips cached artifacts.
+func (f *Fake) downloadArtifact(ctx context.Context, artifact Artifact, dst string, force bool, progress ProgressFunc) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	if !force && isCached(dst, artifact) {
+		if progress != nil {
+			progress(artifact.Size, artifact.Size)
+		}
+		return nil
+	}
+	if !force {
+		if copied, err := f.Cache.copyCached(artifact, dst); copied || err != nil {
+			if err == nil && progress != nil {
+				progress(artifact.Size, artifact.Size)
+			}
+			return err
+		}
+	}
+
+	var done, total int64
+	for _, content := range f.Files[artifact.ID] {
+		total += int64(len(content))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	staging, err := createStaging(dst)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	for name, content := range f.Files[artifact.ID] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(staging, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return err
+		}
+
+		done += int64(len(content))
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	if f.Cache.Unpack {
+		if err := unpackNested(staging, newBudget(extractLimits), 1); err != nil {
+			return err
+		}
+	}
+
+	if err := writeMarker(staging, artifact); err != nil {
+		return err
+	}
+	if err := replaceDir(staging, dst); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.Downloaded = append(f.Downloaded, artifact.ID)
+	f.mu.Unlock()
+
+	return nil
+}
+
+func (f *Fake) Run(filepath string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	f.mu.Lock()
+	f.Opened = append(f.Opened, filepath)
+	f.mu.Unlock()
+
+	return nil
+}
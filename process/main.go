@@ -3,221 +3,777 @@ package process
 import (
 	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/go-github/v62/github"
 	"github.com/pkg/browser"
+	"golang.org/x/sync/errgroup"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+const runsPerPage = 30
+
+type Backend interface {
+	GetOrganizations() ([]Result, error)
+	GetRepositories(organization string, page int) ([]Result, int, error)
+	SearchRepositories(organization string, query string) ([]Result, error)
+	GetWorkflows(organization string, repository string) ([]Result, error)
+	GetDefaultBranch(organization string, repository string) (string, error)
+	GetWorkflowInputs(organization string, repository string, path string, ref string) ([]Input, error)
+	GetEnvironments(organization string, repository string) ([]string, error)
+	DispatchWorkflow(organization string, repository string, workflowId int64, ref string, inputs map[string]string) error
+	GetWorkflowRuns(organization string, repository string, workflowId int64, filter RunFilter, page int) ([]Run, int, error)
+	RerunWorkflowRun(organization string, repository string, runId int64) error
+	RerunFailedJobs(organization string, repository string, runId int64) error
+	CancelWorkflowRun(organization string, repository string, runId int64) error
+	GetJobs(organization string, repository string, runId int64) ([]Job, error)
+	RerunJob(organization string, repository string, jobId int64) error
+	GetJob(organization string, repository string, jobId int64) (Job, error)
+	GetJobLogs(organization string, repository string, jobId int64, offset int64) (string, error)
+	GetArtifacts(organization string, repository string, workflowId int64) ([]Artifact, error)
+	DownloadArtifact(ctx context.Context, organization string, repository string, artifact Artifact, force bool, progress ProgressFunc) error
+	InspectArtifact(ctx context.Context, organization string, repository string, artifact Artifact) (Archive, error)
+	ExtractArtifactEntry(ctx context.Context, organization string, repository string, artifact Artifact, entry ArchiveEntry, progress ProgressFunc) (string, error)
+	DownloadArtifacts(ctx context.Context, organization string, repository string, runId int64, artifacts []Artifact, force bool, progress ArtifactProgressFunc) error
+	Run(filepath string) error
+}
+
+var _ Backend = (*Process)(nil)
+
 type Process struct {
 	token  string
 	client *github.Client
 	ctx    context.Context
+	login  string
+	cache  *Cache
 }
 
+// Result is an organization, a repository or a workflow. Title is what to show
+// in place of the name, when there is something to add.
 type Result struct {
-	ID         int64
-	Name       string
-	Status     string
-	Title      string
-	Conclusion string
+	ID    int64
+	Name  string
+	Title string
+	Owner string
+
+	// Path and State describe workflows.
+	Path  string
+	State string
+
+	// Branch, PushedAt, Visibility and Archived describe repositories, Branch
+	// being their default branch.
+	Branch     string
+	PushedAt   time.Time
+	Visibility string
+	Archived   bool
+}
+
+// Run is a run of a workflow. Name is the workflow's, Title the commit's or
+// pull request's.
+type Run struct {
+	ID          int64
+	Name        string
+	Title       string
+	Status      string
+	Conclusion  string
+	WorkflowID  int64
+	RunNumber   int64
+	Branch      string
+	Event       string
+	Actor       string
+	Attempt     int64
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// Job is a job of a run, Attempt being the attempt of the run it ran in.
+type Job struct {
+	ID          int64
+	Name        string
+	Status      string
+	Conclusion  string
+	Runner      string
+	Attempt     int64
+	StartedAt   time.Time
+	CompletedAt time.Time
+	Steps       []Step
+}
+
+// Step is a step of a job, numbered from 1.
+type Step struct {
+	Number      int64
+	Name        string
+	Status      string
+	Conclusion  string
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// Artifact is an artifact a run uploaded, Size being that of its zip archive.
+type Artifact struct {
+	ID        int64
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Expired   bool
+}
+
+// Duration is the time between StartedAt and CompletedAt, or until now while
+// still running.
+func (r Run) Duration() time.Duration {
+	return duration(r.StartedAt, r.CompletedAt)
+}
+
+func (j Job) Duration() time.Duration {
+	return duration(j.StartedAt, j.CompletedAt)
+}
+
+func (s Step) Duration() time.Duration {
+	return duration(s.StartedAt, s.CompletedAt)
+}
+
+func duration(startedAt time.Time, completedAt time.Time) time.Duration {
+	if startedAt.IsZero() {
+		return 0
+	}
+	if completedAt.IsZero() {
+		return time.Since(startedAt)
+	}
+
+	return completedAt.Sub(startedAt)
+}
+
+// RunFilter narrows down workflow runs on the server. Created takes GitHub's
+// date syntax, e.g. ">=2024-06-01" or "2024-06-01..2024-06-30", and an Actor
+// of "@me" is the authenticated user.
+type RunFilter struct {
+	Branch  string
+	Event   string
+	Actor   string
+	Status  string
+	Created string
+}
+
+func (f RunFilter) IsZero() bool {
+	return f == RunFilter{}
+}
+
+func (f RunFilter) String() string {
+	var parts []string
+	for _, part := range [][2]string{
+		{"branch", f.Branch},
+		{"event", f.Event},
+		{"actor", f.Actor},
+		{"status", f.Status},
+		{"created", f.Created},
+	} {
+		if part[1] != "" {
+			parts = append(parts, part[0]+":"+part[1])
+		}
+	}
+
+	return strings.Join(parts, " ")
 }
 
-func NewProcess(token string) Process {
+func NewProcess(token string, cache *Cache) Process {
 	return Process{
 		token:  token,
 		client: github.NewClient(nil).WithAuthToken(token),
 		ctx:    context.Background(),
+		cache:  cache,
 	}
 }
 
+// GetOrganizations lists the organizations the user is an active member of,
+// after an entry for the user's own account whose Title says so.
 func (p *Process) GetOrganizations() ([]Result, error) {
-
-	githubOrgs, _, err := p.client.Organizations.ListOrgMemberships(p.ctx, &github.ListOrgMembershipsOptions{})
+	login, err := p.getLogin()
 	if err != nil {
-		log.Fatal(err)
 		return nil, err
 	}
+	orgs := []Result{{Name: login, Title: "your own and collaborator repositories"}}
 
-	var orgs []Result
-	for _, org := range githubOrgs {
-		orgs = append(orgs, Result{
-			ID:   org.Organization.GetID(),
-			Name: org.Organization.GetLogin(),
-		})
+	opts := &github.ListOrgMembershipsOptions{State: "active", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		memberships, resp, err := p.client.Organizations.ListOrgMemberships(p.ctx, opts)
+		if err != nil {
+			return nil, classify("list organizations", err)
+		}
+
+		for _, membership := range memberships {
+			orgs = append(orgs, Result{
+				ID:   membership.Organization.GetID(),
+				Name: membership.Organization.GetLogin(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 
 	return orgs, nil
 }
 
-func (p *Process) GetRepositories(organization string) ([]Result, error) {
+// GetRepositories returns a page of the repositories of an organization, or
+// of those the user owns or collaborates on when organization is the user's
+// login, and the next page, 0 after the last. Title is the full name of
+// repositories that belong to someone else.
+func (p *Process) GetRepositories(organization string, page int) ([]Result, int, error) {
+	login, err := p.getLogin()
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var githubRepositories []*github.Repository
-	page := 1
+	var resp *github.Response
+	listOptions := github.ListOptions{Page: page, PerPage: 100}
+	if organization == login {
+		githubRepositories, resp, err = p.client.Repositories.ListByAuthenticatedUser(p.ctx, &github.RepositoryListByAuthenticatedUserOptions{Affiliation: "owner,collaborator", Sort: "full_name", ListOptions: listOptions})
+	} else {
+		githubRepositories, resp, err = p.client.Repositories.ListByOrg(p.ctx, organization, &github.RepositoryListByOrgOptions{Sort: "full_name", ListOptions: listOptions})
+	}
+	if err != nil {
+		return nil, 0, classify("list repositories", err)
+	}
+
+	return repositoryResults(githubRepositories, organization), resp.NextPage, nil
+}
+
+// ErrNoSearch is returned for the user's own account, where search would only
+// find the repositories the user owns and not the ones the user collaborates
+// on. Its repositories can only be filtered once they are loaded.
+var ErrNoSearch = errors.New("the repositories of your own account can't be searched")
+
+// SearchRepositories finds repositories of an organization whose name
+// matches query. It returns ErrNoSearch for the user's own account.
+func (p *Process) SearchRepositories(organization string, query string) ([]Result, error) {
+	login, err := p.getLogin()
+	if err != nil {
+		return nil, err
+	}
+	if organization == login {
+		return nil, ErrNoSearch
+	}
+
+	result, _, err := p.client.Search.Repositories(p.ctx, query+" in:name org:"+organization, &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, classify("search repositories", err)
+	}
+
+	return repositoryResults(result.Repositories, organization), nil
+}
+
+func repositoryResults(githubRepositories []*github.Repository, organization string) []Result {
+	var repositories []Result
+	for _, repository := range githubRepositories {
+		result := Result{
+			ID:         repository.GetID(),
+			Name:       repository.GetName(),
+			Owner:      repository.GetOwner().GetLogin(),
+			Branch:     repository.GetDefaultBranch(),
+			PushedAt:   repository.GetPushedAt().Time,
+			Visibility: repository.GetVisibility(),
+			Archived:   repository.GetArchived(),
+		}
+		if result.Owner != organization {
+			result.Title = repository.GetFullName()
+		}
+		repositories = append(repositories, result)
+	}
+
+	return repositories
+}
+
+func (p *Process) GetWorkflows(organization string, repository string) ([]Result, error) {
+	var githubWorkflows []*github.Workflow
+	opts := &github.ListOptions{PerPage: 100}
 	for {
-		r, _, err := p.client.Repositories.ListByOrg(p.ctx, organization, &github.RepositoryListByOrgOptions{Sort: "full_name", ListOptions: github.ListOptions{Page: page, PerPage: 100}})
+		w, resp, err := p.client.Actions.ListWorkflows(p.ctx, organization, repository, opts)
 		if err != nil {
-			log.Fatal(err)
-			return nil, err
+			return nil, classify("list workflows", err)
 		}
 
-		if len(r) == 0 {
+		githubWorkflows = append(githubWorkflows, w.Workflows...)
+
+		if resp.NextPage == 0 {
 			break
 		}
-
-		githubRepositories = append(githubRepositories, r...)
-
-		page++
+		opts.Page = resp.NextPage
 	}
 
-	var repositories []Result
-	for _, repository := range githubRepositories {
-		repositories = append(repositories, Result{
-			ID:   repository.GetID(),
-			Name: repository.GetName(),
+	var workflows []Result
+	for _, workflow := range githubWorkflows {
+		workflows = append(workflows, Result{
+			ID:    workflow.GetID(),
+			Name:  workflow.GetName(),
+			Path:  workflow.GetPath(),
+			State: workflow.GetState(),
 		})
 	}
 
-	return repositories, nil
+	return workflows, nil
 }
 
-func (p *Process) GetWorkflowRuns(organization string, repository string) ([]Result, error) {
-	githubRuns, _, err := p.client.Actions.ListRepositoryWorkflowRuns(p.ctx, organization, repository, &github.ListWorkflowRunsOptions{})
+// GetWorkflowRuns returns a single page of runs together with the number of
+// the next page, which is 0 when there are no more runs. A workflowId of 0
+// returns the runs of every workflow in the repository.
+func (p *Process) GetWorkflowRuns(organization string, repository string, workflowId int64, filter RunFilter, page int) ([]Run, int, error) {
+	actor := filter.Actor
+	if actor == "@me" {
+		login, err := p.getLogin()
+		if err != nil {
+			return nil, 0, err
+		}
+		actor = login
+	}
+
+	opts := &github.ListWorkflowRunsOptions{
+		Branch:      filter.Branch,
+		Event:       filter.Event,
+		Actor:       actor,
+		Status:      filter.Status,
+		Created:     filter.Created,
+		ListOptions: github.ListOptions{Page: page, PerPage: runsPerPage},
+	}
+	var githubRuns *github.WorkflowRuns
+	var resp *github.Response
+	var err error
+	if workflowId == 0 {
+		githubRuns, resp, err = p.client.Actions.ListRepositoryWorkflowRuns(p.ctx, organization, repository, opts)
+	} else {
+		githubRuns, resp, err = p.client.Actions.ListWorkflowRunsByID(p.ctx, organization, repository, workflowId, opts)
+	}
 	if err != nil {
-		return nil, err
+		return nil, 0, classify("list workflow runs", err)
 	}
 
-	var runs []Result
+	var runs []Run
 	for _, run := range githubRuns.WorkflowRuns {
-		runs = append(runs, Result{
+		result := Run{
 			ID:         run.GetID(),
 			Name:       run.GetName(),
+			Status:     run.GetStatus(),
 			Title:      run.GetDisplayTitle(),
 			Conclusion: run.GetConclusion(),
-		})
+			WorkflowID: run.GetWorkflowID(),
+			RunNumber:  int64(run.GetRunNumber()),
+			Branch:     run.GetHeadBranch(),
+			Event:      run.GetEvent(),
+			Actor:      run.GetTriggeringActor().GetLogin(),
+			Attempt:    int64(run.GetRunAttempt()),
+			StartedAt:  run.GetRunStartedAt().Time,
+		}
+		// a run has no completion time of its own, it was last updated when it
+		// completed
+		if result.Status == "completed" {
+			result.CompletedAt = run.GetUpdatedAt().Time
+		}
+		runs = append(runs, result)
 	}
 
-	return runs, nil
+	return runs, resp.NextPage, nil
 }
 
-func (p *Process) GetArtifacts(organization string, repository string, workflowId int64) ([]Result, error) {
-	githubArtifacts, _, err := p.client.Actions.ListWorkflowRunArtifacts(p.ctx, organization, repository, workflowId, &github.ListOptions{})
-	if err != nil {
-		log.Fatal(err)
-		return nil, err
+func (p *Process) RerunWorkflowRun(organization string, repository string, runId int64) error {
+	_, err := p.client.Actions.RerunWorkflowByID(p.ctx, organization, repository, runId)
+	return classify("rerun workflow run", accepted(err))
+}
+
+func (p *Process) RerunFailedJobs(organization string, repository string, runId int64) error {
+	_, err := p.client.Actions.RerunFailedJobsByID(p.ctx, organization, repository, runId)
+	return classify("rerun failed jobs", accepted(err))
+}
+
+func (p *Process) CancelWorkflowRun(organization string, repository string, runId int64) error {
+	_, err := p.client.Actions.CancelWorkflowRunByID(p.ctx, organization, repository, runId)
+	return classify("cancel workflow run", accepted(err))
+}
+
+func (p *Process) RerunJob(organization string, repository string, jobId int64) error {
+	_, err := p.client.Actions.RerunJobByID(p.ctx, organization, repository, jobId)
+	return classify("rerun job", accepted(err))
+}
+
+// accepted treats a 202 Accepted, which go-github reports as an error, as
+// success.
+func accepted(err error) error {
+	var acceptedErr *github.AcceptedError
+	if errors.As(err, &acceptedErr) {
+		return nil
 	}
 
-	var artifacts []Result
-	for _, artifact := range githubArtifacts.Artifacts {
-		artifacts = append(artifacts, Result{
-			ID:   artifact.GetID(),
-			Name: artifact.GetName(),
-		})
+	return err
+}
+
+// GetJobs returns the jobs of every attempt of a run, each with its steps.
+func (p *Process) GetJobs(organization string, repository string, runId int64) ([]Job, error) {
+	var githubJobs []*github.WorkflowJob
+	opts := &github.ListWorkflowJobsOptions{Filter: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		j, resp, err := p.client.Actions.ListWorkflowJobs(p.ctx, organization, repository, runId, opts)
+		if err != nil {
+			return nil, classify("list jobs", err)
+		}
+
+		githubJobs = append(githubJobs, j.Jobs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 
-	return artifacts, nil
+	var jobs []Job
+	for _, job := range githubJobs {
+		jobs = append(jobs, newJob(job))
+	}
+
+	return jobs, nil
 }
 
-func (p *Process) DownloadArtifact(organization string, repository string, artifactId int64) error {
-	url, _, err := p.client.Actions.DownloadArtifact(p.ctx, organization, repository, artifactId, 10)
+func (p *Process) GetJob(organization string, repository string, jobId int64) (Job, error) {
+	job, _, err := p.client.Actions.GetWorkflowJobByID(p.ctx, organization, repository, jobId)
 	if err != nil {
-		log.Fatal(err)
-		return err
+		return Job{}, classify("get job", err)
 	}
 
-	downloadZip(url.String())
+	return newJob(job), nil
+}
 
-	unzip(artifactId)
+func newJob(job *github.WorkflowJob) Job {
+	var steps []Step
+	for _, step := range job.Steps {
+		steps = append(steps, Step{
+			Number:      step.GetNumber(),
+			Name:        step.GetName(),
+			Status:      step.GetStatus(),
+			Conclusion:  step.GetConclusion(),
+			StartedAt:   step.GetStartedAt().Time,
+			CompletedAt: step.GetCompletedAt().Time,
+		})
+	}
 
-	return nil
+	return Job{
+		ID:          job.GetID(),
+		Name:        job.GetName(),
+		Status:      job.GetStatus(),
+		Conclusion:  job.GetConclusion(),
+		Runner:      job.GetRunnerName(),
+		Attempt:     job.GetRunAttempt(),
+		StartedAt:   job.GetStartedAt().Time,
+		CompletedAt: job.GetCompletedAt().Time,
+		Steps:       steps,
+	}
 }
 
-// FIXME: return err instead
-func downloadZip(url string) {
-	err := os.MkdirAll("output", 0755)
+// GetJobLogs returns the log of a job from offset on. Tailing a log only asks
+// for what was added since, the log's blob URL takes Range requests.
+func (p *Process) GetJobLogs(organization string, repository string, jobId int64, offset int64) (string, error) {
+	url, _, err := p.client.Actions.GetWorkflowJobLogs(p.ctx, organization, repository, jobId, 10)
 	if err != nil {
-		panic(err)
+		return "", classify("get job logs", err)
 	}
 
-	output, err := os.Create("output/file.zip")
+	return downloadLog(p.ctx, url.String(), offset)
+}
+
+func downloadLog(ctx context.Context, url string, offset int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		log.Fatal(err)
+		return "", classify("download job logs", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
-	defer output.Close()
 
-	resp, err := http.Get(url)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		log.Fatal(err)
+		return "", classify("download job logs", err)
 	}
 	defer resp.Body.Close()
 
-	_, err = io.Copy(output, resp.Body)
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		// nothing was added since
+		return "", nil
+	case http.StatusOK, http.StatusPartialContent:
+	default:
+		return "", statusError("download job logs", resp)
+	}
+
+	logs, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatal(err)
+		return "", classify("download job logs", err)
 	}
 
-	// log.Println("ZIP file downloaded successfully.")
+	// the whole log, from a server that ignored the range
+	if resp.StatusCode == http.StatusOK {
+		logs = logs[min(offset, int64(len(logs))):]
+	}
+
+	return string(logs), nil
 }
 
-// FIXME: return err instead
-func unzip(artifactId int64) {
-	dst := fmt.Sprintf("output/%d", artifactId)
-	archive, err := zip.OpenReader("output/file.zip")
+func (p *Process) getLogin() (string, error) {
+	if p.login != "" {
+		return p.login, nil
+	}
+
+	user, _, err := p.client.Users.Get(p.ctx, "")
 	if err != nil {
-		panic(err)
+		return "", classify("get authenticated user", err)
 	}
-	defer archive.Close()
+	p.login = user.GetLogin()
 
-	for _, f := range archive.File {
-		filePath := filepath.Join(dst, f.Name)
-		// fmt.Println("unzipping file ", filePath)
+	return p.login, nil
+}
 
-		if !strings.HasPrefix(filePath, filepath.Clean(dst)+string(os.PathSeparator)) {
-			fmt.Println("invalid file path")
-			return
+func (p *Process) GetArtifacts(organization string, repository string, workflowId int64) ([]Artifact, error) {
+	var githubArtifacts []*github.Artifact
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		a, resp, err := p.client.Actions.ListWorkflowRunArtifacts(p.ctx, organization, repository, workflowId, opts)
+		if err != nil {
+			return nil, classify("list artifacts", err)
 		}
 
-		if f.FileInfo().IsDir() {
-			// fmt.Println("creating directory...")
-			os.MkdirAll(filePath, os.ModePerm)
-			continue
-		}
+		githubArtifacts = append(githubArtifacts, a.Artifacts...)
 
-		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			panic(err)
+		if resp.NextPage == 0 {
+			break
 		}
+		opts.Page = resp.NextPage
+	}
+
+	var artifacts []Artifact
+	for _, artifact := range githubArtifacts {
+		artifacts = append(artifacts, Artifact{
+			ID:        artifact.GetID(),
+			Name:      artifact.GetName(),
+			Size:      artifact.GetSizeInBytes(),
+			CreatedAt: artifact.GetCreatedAt().Time,
+			ExpiresAt: artifact.GetExpiresAt().Time,
+			Expired:   artifact.GetExpired(),
+		})
+	}
+
+	return artifacts, nil
+}
 
-		dstFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+// ProgressFunc is called as a download makes progress. Total is -1 when the
+// size of the download isn't known.
+type ProgressFunc func(done int64, total int64)
+
+// ArtifactProgressFunc is a ProgressFunc for one of several downloads.
+type ArtifactProgressFunc func(artifactId int64, done int64, total int64)
+
+// downloadConcurrency bounds the number of artifacts DownloadArtifacts
+// fetches at the same time.
+const downloadConcurrency = 4
+
+// DownloadArtifact stops when ctx is cancelled, returning ctx.Err(). Unless
+// force is set, an artifact that is already in the cache isn't downloaded
+// again. Other artifacts may be evicted from the cache to make room.
+func (p *Process) DownloadArtifact(ctx context.Context, organization string, repository string, artifact Artifact, force bool, progress ProgressFunc) error {
+	dst := p.cache.ArtifactDir(artifact.ID)
+	if err := p.downloadArtifact(ctx, organization, repository, artifact, dst, force, progress); err != nil {
+		return err
+	}
+
+	// a full cache is no reason to fail the download
+	p.cache.Evict(dst)
+	return nil
+}
+
+// DownloadArtifacts downloads artifacts of a run to Cache.RunDir, a few at a
+// time. The first failure cancels the downloads that are still running.
+func (p *Process) DownloadArtifacts(ctx context.Context, organization string, repository string, runId int64, artifacts []Artifact, force bool, progress ArtifactProgressFunc) error {
+	// bad names are refused before anything is downloaded
+	dsts := make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		dst, err := artifactDir(p.cache.RunDir(runId), artifact)
 		if err != nil {
-			panic(err)
+			return err
 		}
+		dsts[i] = dst
+	}
 
-		fileInArchive, err := f.Open()
-		if err != nil {
-			panic(err)
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(downloadConcurrency)
+
+	for i, artifact := range artifacts {
+		dst := dsts[i]
+		group.Go(func() error {
+			return p.downloadArtifact(ctx, organization, repository, artifact, dst, force, func(done int64, total int64) {
+				if progress != nil {
+					progress(artifact.ID, done, total)
+				}
+			})
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	p.cache.Evict(p.cache.RunDir(runId))
+	return nil
+}
+
+// downloadArtifact downloads the archive to a temporary file and extracts it
+// to a temporary directory that only replaces dst once extraction succeeded,
+// so failures and concurrent downloads never leave a partial dst behind.
+func (p *Process) downloadArtifact(ctx context.Context, organization string, repository string, artifact Artifact, dst string, force bool, progress ProgressFunc) error {
+	if !force && isCached(dst, artifact) {
+		if progress != nil {
+			progress(artifact.Size, artifact.Size)
 		}
+		return nil
+	}
+	// GitHub may no longer have it, but the cache does
+	if !force {
+		if copied, err := p.cache.copyCached(artifact, dst); copied || err != nil {
+			if err == nil && progress != nil {
+				progress(artifact.Size, artifact.Size)
+			}
+			return err
+		}
+	}
 
-		if _, err := io.Copy(dstFile, fileInArchive); err != nil {
-			panic(err)
+	url, _, err := p.client.Actions.DownloadArtifact(ctx, organization, repository, artifact.ID, 10)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		return classify("download artifact", err)
+	}
 
-		dstFile.Close()
-		fileInArchive.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return classify("download artifact", err)
 	}
+
+	archive, err := os.CreateTemp(filepath.Dir(dst), fmt.Sprintf(".%d-*.zip", artifact.ID))
+	if err != nil {
+		return classify("download artifact", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := downloadZip(ctx, url.String(), archive, progress); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return classify("download artifact", err)
+	}
+
+	return unzip(archive.Name(), dst, artifact, p.cache.Unpack)
+}
+
+// progressWriter counts the bytes written through it.
+type progressWriter struct {
+	done     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	w.done += int64(len(b))
+	if w.progress != nil {
+		w.progress(w.done, w.total)
+	}
+
+	return len(b), nil
+}
+
+// downloadZip writes the archive at url to output, checking that it got as
+// many bytes as the server announced.
+func downloadZip(ctx context.Context, url string, output io.Writer, progress ProgressFunc) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return classify("download artifact", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return classify("download artifact", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError("download artifact", resp)
+	}
+
+	counter := &progressWriter{total: resp.ContentLength, progress: progress}
+	if _, err = io.Copy(io.MultiWriter(output, counter), resp.Body); err != nil {
+		return classify("download artifact", err)
+	}
+	if counter.total >= 0 && counter.done != counter.total {
+		return &Error{Kind: Network, Op: "download artifact", Err: fmt.Errorf("got %d of %d bytes", counter.done, counter.total)}
+	}
+
+	return nil
+}
+
+// unzip extracts the archive next to dst first and then moves it into place,
+// replacing what a previous download left there, together with the cache
+// marker for artifact. Archives inside it are unpacked when unpack is set.
+func unzip(archivePath string, dst string, artifact Artifact, unpack bool) error {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return extractionError("open archive", err)
+	}
+	defer archive.Close()
+
+	staging, err := createStaging(dst)
+	if err != nil {
+		return extractionError("extract archive", err)
+	}
+	defer os.RemoveAll(staging)
+	// MkdirTemp leaves the directory to its owner only
+	if err := os.Chmod(staging, 0755); err != nil {
+		return extractionError("extract archive", err)
+	}
+
+	b := newBudget(extractLimits)
+	if err := extractZip(&archive.Reader, staging, b); err != nil {
+		return err
+	}
+	if unpack {
+		if err := unpackNested(staging, b, 1); err != nil {
+			return err
+		}
+	}
+
+	if err := writeMarker(staging, artifact); err != nil {
+		return extractionError("extract archive", err)
+	}
+
+	return replaceDir(staging, dst)
 }
 
 func (p *Process) Run(filepath string) error {
 
 	if strings.Contains(filepath, "webm") {
-		browser.OpenFile(filepath)
+		if err := browser.OpenFile(filepath); err != nil {
+			return classify("open "+filepath, err)
+		}
 	}
 	if strings.Contains(filepath, "png") {
-		browser.OpenFile(filepath)
+		if err := browser.OpenFile(filepath); err != nil {
+			return classify("open "+filepath, err)
+		}
 	}
 
 	if strings.Contains(filepath, "zip") {
@@ -226,14 +782,12 @@ func (p *Process) Run(filepath string) error {
 		err := cmd.Start()
 		if err != nil {
 			// TODO: try pnpm exec playwright
-			log.Fatal(err)
-			return err
+			return classify("start playwright", err)
 		}
 
 		err = cmd.Wait()
 		if err != nil {
-			log.Fatal(err)
-			return err
+			return classify("show trace", err)
 		}
 
 	}
@@ -0,0 +1,84 @@
+package process
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadArtifactsRefusesBadNames(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	// without a client, starting any download would panic
+	p := &Process{cache: cache}
+
+	artifacts := []Artifact{{ID: 1, Name: "report"}, {ID: 2, Name: ".."}}
+	err := p.DownloadArtifacts(context.Background(), "acme", "web", 7, artifacts, false, nil)
+	if KindOf(err) != Extraction {
+		t.Fatalf("DownloadArtifacts() = %v, want an extraction error", err)
+	}
+
+	if _, err := os.Stat(cache.RunDir(7)); !os.IsNotExist(err) {
+		t.Errorf("run directory was created: %v", err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	start := time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     time.Duration
+	}{
+		{"queued run", Run{Status: "queued"}.Duration(), 0},
+		{"completed run", Run{StartedAt: start, CompletedAt: start.Add(4 * time.Minute)}.Duration(), 4 * time.Minute},
+		{"completed job", Job{StartedAt: start, CompletedAt: start.Add(90 * time.Second)}.Duration(), 90 * time.Second},
+		{"completed step", Step{StartedAt: start, CompletedAt: start.Add(2 * time.Second)}.Duration(), 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if tt.duration != tt.want {
+			t.Errorf("%s: Duration() = %v, want %v", tt.name, tt.duration, tt.want)
+		}
+	}
+
+	running := Job{StartedAt: time.Now().Add(-time.Minute)}
+	if d := running.Duration(); d < time.Minute || d > 2*time.Minute {
+		t.Errorf("running job: Duration() = %v, want about a minute", d)
+	}
+}
+
+func TestDownloadLog(t *testing.T) {
+	log := "2024-06-03T09:30:00.0000000Z first\n2024-06-03T09:30:01.0000000Z second\n"
+	server := rangeServer(t, []byte(log))
+	first := int64(strings.Index(log, "2024-06-03T09:30:01"))
+
+	tests := []struct {
+		name    string
+		path    string
+		offset  int64
+		want    string
+		wantErr bool
+	}{
+		{"whole log", "/current", 0, log, false},
+		{"added", "/current", first, log[first:], false},
+		{"nothing added", "/current", int64(len(log)), "", false},
+		{"range ignored", "/whole", first, log[first:], false},
+		{"range ignored, nothing added", "/whole", int64(len(log)), "", false},
+		{"refused", "/expired", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := downloadLog(context.Background(), server.URL+tt.path, tt.offset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("downloadLog() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("downloadLog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package process
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNoRanges is returned when the server ignores Range requests, the
+// artifact has to be downloaded as a whole then.
+var ErrNoRanges = errors.New("server doesn't support range requests")
+
+// rangeChunkSize is the least a rangeReader fetches at once. zip reads the
+// central directory and entries in small sequential pieces.
+const rangeChunkSize = 1 << 20
+
+// rangeReader reads a remote file with Range requests, keeping the last chunk
+// it fetched. URLs that stop working are replaced by refresh, GitHub's signed
+// URLs expire long before a large entry is fetched. It isn't safe for
+// concurrent use.
+type rangeReader struct {
+	ctx     context.Context
+	url     string
+	refresh func() (string, error)
+	size    int64
+	chunk   []byte
+	offset  int64
+}
+
+// newRangeReader asks for the first byte to learn the size of the file and
+// whether the server honors ranges at all.
+func newRangeReader(ctx context.Context, url string, refresh func() (string, error)) (*rangeReader, error) {
+	r := &rangeReader{ctx: ctx, url: url, refresh: refresh}
+
+	resp, err := r.get(0, 1)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	// e.g. bytes 0-0/1234
+	_, total, _ := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	r.size, err = strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return nil, ErrNoRanges
+	}
+
+	return r, nil
+}
+
+// get asks for length bytes at offset, once more with a new URL when the
+// current one was refused.
+func (r *rangeReader) get(offset int64, length int64) (*http.Response, error) {
+	resp, err := r.request(offset, length)
+	if err != nil {
+		return nil, err
+	}
+
+	if expired := resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden; expired && r.refresh != nil {
+		resp.Body.Close()
+		if r.url, err = r.refresh(); err != nil {
+			return nil, err
+		}
+		if resp, err = r.request(offset, length); err != nil {
+			return nil, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp, nil
+	case http.StatusOK:
+		// closing the body early stops the transfer of the whole file
+		resp.Body.Close()
+		return nil, ErrNoRanges
+	}
+
+	resp.Body.Close()
+	return nil, statusError("read artifact", resp)
+}
+
+func (r *rangeReader) request(offset int64, length int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, classify("read artifact", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, classify("read artifact", err)
+	}
+
+	return resp, nil
+}
+
+func (r *rangeReader) fetch(offset int64, length int64) error {
+	resp, err := r.get(offset, length)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	chunk := make([]byte, length)
+	if _, err := io.ReadFull(resp.Body, chunk); err != nil {
+		return classify("read artifact", err)
+	}
+	r.chunk, r.offset = chunk, offset
+
+	return nil
+}
+
+func (r *rangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for n < len(p) && off+int64(n) < r.size {
+		pos := off + int64(n)
+		if pos < r.offset || pos >= r.offset+int64(len(r.chunk)) {
+			length := min(max(int64(len(p)-n), rangeChunkSize), r.size-pos)
+			if err := r.fetch(pos, length); err != nil {
+				return n, err
+			}
+		}
+		n += copy(p[n:], r.chunk[pos-r.offset:])
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// InspectArtifact lists the files of an artifact by reading only the zip's
+// central directory from GitHub. It returns ErrNoRanges when that isn't
+// possible.
+func (p *Process) InspectArtifact(ctx context.Context, organization string, repository string, artifact Artifact) (Archive, error) {
+	archive, err := p.remoteArchive(ctx, organization, repository, artifact)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	return Archive{Path: artifact.Name + ".zip", Artifact: artifact, Entries: archiveEntries(archive)}, nil
+}
+
+// ExtractArtifactEntry fetches and extracts a single file of an artifact,
+// returning where it was extracted to. Progress is reported in bytes of the
+// extracted file. It returns ErrNoRanges when that isn't possible.
+func (p *Process) ExtractArtifactEntry(ctx context.Context, organization string, repository string, artifact Artifact, entry ArchiveEntry, progress ProgressFunc) (string, error) {
+	archive, err := p.remoteArchive(ctx, organization, repository, artifact)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := remoteEntryDir(artifact)
+	if err != nil {
+		return "", extractionError("extract "+entry.Path, err)
+	}
+
+	return extractEntry(archive, entry, dir, progress)
+}
+
+// remoteArchive asks for a new download URL every time, and again whenever
+// the URL is refused, GitHub's expire after a minute.
+func (p *Process) remoteArchive(ctx context.Context, organization string, repository string, artifact Artifact) (*zip.Reader, error) {
+	refresh := func() (string, error) {
+		url, _, err := p.client.Actions.DownloadArtifact(ctx, organization, repository, artifact.ID, 10)
+		if err != nil {
+			return "", classify("inspect artifact", err)
+		}
+		return url.String(), nil
+	}
+
+	url, err := refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newRangeReader(ctx, url, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(r, r.size)
+	if err != nil {
+		var e *Error
+		if errors.Is(err, ErrNoRanges) || errors.As(err, &e) {
+			return nil, err
+		}
+		return nil, extractionError("open archive", err)
+	}
+
+	return archive, nil
+}
+
+// remoteEntryDir is where the files of an artifact fetched one by one go,
+// away from the cache which only holds complete artifacts.
+func remoteEntryDir(artifact Artifact) (string, error) {
+	return scratchDir(fmt.Sprintf("artifact-%d", artifact.ID))
+}
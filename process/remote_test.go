@@ -0,0 +1,129 @@
+package process
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rangeServer serves content with Range support at /current, and refuses
+// /expired like GitHub refuses signed URLs that expired.
+func rangeServer(t *testing.T, content []byte) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/current":
+			http.ServeContent(w, r, "artifact.zip", time.Time{}, bytes.NewReader(content))
+		case "/whole":
+			w.Write(content)
+		default:
+			http.Error(w, "expired", http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRangeReaderReadAt(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), (rangeChunkSize*5/2)/16)
+	server := rangeServer(t, content)
+
+	r, err := newRangeReader(context.Background(), server.URL+"/current", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.size != int64(len(content)) {
+		t.Fatalf("size = %d, want %d", r.size, len(content))
+	}
+
+	tests := []struct {
+		name    string
+		offset  int64
+		length  int
+		wantN   int
+		wantErr error
+	}{
+		{"start", 0, 10, 10, nil},
+		{"within the chunk", 100, 50, 50, nil},
+		{"across chunks", rangeChunkSize - 5, 10, 10, nil},
+		{"larger than a chunk", 10, rangeChunkSize + 100, rangeChunkSize + 100, nil},
+		{"up to the end", int64(len(content)) - 7, 7, 7, nil},
+		{"past the end", int64(len(content)) - 7, 10, 7, io.EOF},
+		{"after the end", int64(len(content)), 10, 0, io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := make([]byte, tt.length)
+			n, err := r.ReadAt(p, tt.offset)
+			if n != tt.wantN || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadAt() = %d, %v, want %d, %v", n, err, tt.wantN, tt.wantErr)
+			}
+			if want := content[tt.offset : tt.offset+int64(n)]; !bytes.Equal(p[:n], want) {
+				t.Fatalf("ReadAt() read %q..., want %q...", p[:min(n, 16)], want[:min(n, 16)])
+			}
+		})
+	}
+}
+
+func TestRangeReaderWithoutRanges(t *testing.T) {
+	server := rangeServer(t, []byte("whole"))
+
+	_, err := newRangeReader(context.Background(), server.URL+"/whole", nil)
+	if !errors.Is(err, ErrNoRanges) {
+		t.Fatalf("newRangeReader() = %v, want %v", err, ErrNoRanges)
+	}
+}
+
+func TestRangeReaderRefreshesExpiredURLs(t *testing.T) {
+	content := []byte(strings.Repeat("x", 100))
+	server := rangeServer(t, content)
+
+	refreshed := 0
+	refresh := func() (string, error) {
+		refreshed++
+		return server.URL + "/current", nil
+	}
+
+	r, err := newRangeReader(context.Background(), server.URL+"/current", refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the URL expires halfway through
+	r.url, r.chunk = server.URL+"/expired", nil
+	p := make([]byte, 100)
+	if n, err := r.ReadAt(p, 0); n != 100 || err != nil {
+		t.Fatalf("ReadAt() = %d, %v", n, err)
+	}
+	if refreshed != 1 {
+		t.Errorf("refreshed %d times, want once", refreshed)
+	}
+
+	// without a way to refresh, the error is the server's
+	r.url, r.chunk, r.refresh = server.URL+"/expired", nil, nil
+	if _, err := r.ReadAt(p, 0); KindOf(err) != Auth {
+		t.Errorf("ReadAt() = %v, want an auth error", err)
+	}
+}
+
+func TestRangeReaderCancel(t *testing.T) {
+	server := rangeServer(t, []byte(strings.Repeat("x", 100)))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r, err := newRangeReader(ctx, server.URL+"/current", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	r.chunk = nil
+	if _, err := r.ReadAt(make([]byte, 10), 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("ReadAt() = %v, want %v", err, context.Canceled)
+	}
+}
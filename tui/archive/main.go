@@ -0,0 +1,193 @@
+package archive
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/format"
+	"github.com/real-erik/platui/tui/list"
+)
+
+// Model browses a zip archive like a directory. Archives opened from inside
+// an archive are stacked on top of it, esc leaves them in turn and returns to
+// the directory each was opened from.
+type Model struct {
+	list     list.Model
+	archives []process.Archive
+	dirs     []string
+	dir      string
+	items    []process.ArchiveEntry
+	notice   string
+	height   int
+	width    int
+}
+
+// openable are the files that can be opened, as in the filepicker.
+var openable = []string{".zip", ".webm", ".png"}
+
+func NewModel() Model {
+	return Model{
+		list: list.NewModel("Archive"),
+	}
+}
+
+// ExtractMsg asks for a file of the archive to be extracted and opened.
+type ExtractMsg struct {
+	Archive process.Archive
+	Entry   process.ArchiveEntry
+}
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+type BackMsg struct{}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) current() process.Archive {
+	return m.archives[len(m.archives)-1]
+}
+
+func description(entry process.ArchiveEntry) string {
+	if entry.Dir {
+		return "directory"
+	}
+
+	desc := format.Bytes(entry.Size)
+	if !entry.Modified.IsZero() {
+		desc += " · " + format.Ago(entry.Modified)
+	}
+
+	return desc
+}
+
+// show lists the entries of dir in the current archive.
+func (m Model) show(dir string) Model {
+	m.dir = dir
+	m.items = m.current().Children(dir)
+
+	items := []list.Item{}
+	for _, entry := range m.items {
+		title := entry.Name()
+		if entry.Dir {
+			title += "/"
+		}
+		items = append(items, list.Item{
+			Title:       title,
+			Description: description(entry),
+		})
+	}
+
+	title := filepath.Base(m.current().Path)
+	if dir != "" {
+		title += "/" + dir
+	}
+	m.list.SetTitle(title)
+	m.list, _ = m.list.Update(items)
+	m.resizeList()
+	return m
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.notice)
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case process.Archive:
+		if len(m.archives) > 0 {
+			m.dirs = append(m.dirs, m.dir)
+		}
+		m.archives = append(m.archives, msg)
+		m.notice = ""
+		return m.show(""), nil
+
+	case tea.KeyMsg:
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	if cmd != nil {
+		listMsg := cmd()
+		switch listMsg.(type) {
+		case list.Msg:
+			listMsg := listMsg.(list.Msg)
+			switch listMsg.Direction {
+			case list.Forward:
+				entry := m.items[listMsg.Item]
+				if entry.Dir {
+					return m.show(entry.Path), nil
+				}
+				if !slices.Contains(openable, strings.ToLower(path.Ext(entry.Path))) {
+					m.notice = fmt.Sprintf("%s can't be opened, only traces, videos and screenshots can.", entry.Name())
+					m.resizeList()
+					return m, nil
+				}
+
+				archive := m.current()
+				m.notice = "Extracting " + entry.Name() + "..."
+				if archive.Remote() {
+					m.notice = "Fetching " + entry.Name() + "..."
+				}
+				m.resizeList()
+				cmd = func() tea.Msg {
+					return ExtractMsg{Archive: archive, Entry: entry}
+				}
+
+			case list.Back:
+				switch {
+				case m.dir != "":
+					parent := path.Dir(m.dir)
+					if parent == "." {
+						parent = ""
+					}
+					return m.show(parent), nil
+
+				case len(m.archives) > 1:
+					dir := m.dirs[len(m.dirs)-1]
+					m.archives = m.archives[:len(m.archives)-1]
+					m.dirs = m.dirs[:len(m.dirs)-1]
+					return m.show(dir), nil
+				}
+
+				m.archives = nil
+				m.dirs = nil
+				cmd = func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		default:
+			// this is a command from bubbletea list, let it pass through
+		}
+	}
+
+	return m, cmd
+
+}
+
+func (m Model) View() string {
+	return m.list.ViewWithBar(m.notice)
+}
@@ -0,0 +1,51 @@
+package archive
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+)
+
+func TestBackReturnsToTheDirectoryOfTheOuterArchive(t *testing.T) {
+	outer := process.Archive{Path: "report.zip", Entries: []process.ArchiveEntry{
+		{Path: "data", Dir: true},
+		{Path: "data/attachments", Dir: true},
+		{Path: "data/attachments/screenshots.zip"},
+	}}
+	inner := process.Archive{Path: "screenshots.zip", Entries: []process.ArchiveEntry{
+		{Path: "resources", Dir: true},
+	}}
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+
+	m := NewModel()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m, _ = m.Update(outer)
+	m = m.show("data/attachments")
+	m, _ = m.Update(inner)
+	m = m.show("resources")
+
+	steps := []struct {
+		archive string
+		dir     string
+	}{
+		{"screenshots.zip", ""},
+		{"report.zip", "data/attachments"},
+		{"report.zip", "data"},
+		{"report.zip", ""},
+	}
+	for _, step := range steps {
+		m, _ = m.Update(esc)
+		if m.current().Path != step.archive || m.dir != step.dir {
+			t.Fatalf("after esc: in %s/%s, want %s/%s", m.current().Path, m.dir, step.archive, step.dir)
+		}
+	}
+
+	m, cmd := m.Update(esc)
+	if cmd == nil {
+		t.Fatal("esc in the outermost archive didn't leave it")
+	}
+	if _, ok := cmd().(BackMsg); !ok || len(m.archives) != 0 || len(m.dirs) != 0 {
+		t.Errorf("esc in the outermost archive = %T, %d archives and %d directories left", cmd(), len(m.archives), len(m.dirs))
+	}
+}
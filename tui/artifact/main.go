@@ -1,47 +1,193 @@
 package artifact
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/real-erik/platui/process"
-
+	"github.com/real-erik/platui/tui/format"
 	"github.com/real-erik/platui/tui/list"
 )
 
 type Model struct {
-	list    list.Model
-	items   []process.Result
+	list   list.Model
+	items  []process.Artifact
+	cache  *process.Cache
+	notice string
+	height int
+	width  int
 }
 
-func NewModel() Model {
+var (
+	downloadAllKey = key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "download all"))
+	refreshKey     = key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "download again"))
+	inspectKey     = key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "inspect"))
+)
+
+func NewModel(cache *process.Cache) Model {
+	list := list.NewModel("Artifacts")
+	list.AddKeys(downloadAllKey, refreshKey, inspectKey)
+
 	return Model{
-		list:    list.NewModel("Artifacts"),
+		list:  list,
+		cache: cache,
 	}
 }
 
+// Notice is shown above the list until the next key press.
+type Notice string
+
+// CacheChanged updates which artifacts are marked as cached.
+type CacheChanged struct{}
+
 type BackMsg struct{}
 
+// ForwardMsg opens an artifact, from the cache unless Force is set.
 type ForwardMsg struct {
-	Payload process.Result
+	Payload process.Artifact
+	Force   bool
+}
+
+// InspectMsg asks for the files of an artifact without downloading it.
+type InspectMsg struct {
+	Payload process.Artifact
+}
+
+// DownloadAllMsg asks for every artifact that hasn't expired or is cached to
+// be downloaded.
+type DownloadAllMsg struct {
+	Payload []process.Artifact
 }
 
 func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+func (m Model) description(artifact process.Artifact) string {
+	parts := []string{format.Bytes(artifact.Size)}
+	if m.cache.IsCached(artifact) {
+		parts = append([]string{"✓ cached"}, parts...)
+	}
+	if created := format.Ago(artifact.CreatedAt); created != "" {
+		parts = append(parts, "created "+created)
+	}
+	if artifact.Expired {
+		parts = append(parts, "expired")
+	} else if expires := format.Until(artifact.ExpiresAt); expires != "" {
+		parts = append(parts, "expires "+expires)
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// title estimates what downloading every artifact that is left would take.
+func title(artifacts []process.Artifact) string {
+	var count int
+	var total int64
+	for _, artifact := range artifacts {
+		if !artifact.Expired {
+			count++
+			total += artifact.Size
+		}
+	}
+
+	return fmt.Sprintf("Artifacts · %d available · %s total", count, format.Bytes(total))
+}
+
+func (m Model) listItems() []list.Item {
+	items := []list.Item{}
+	for _, resultItem := range m.items {
+		items = append(items, list.Item{
+			Title:       resultItem.Name,
+			Description: m.description(resultItem),
+			// expired artifacts are listed disabled, GitHub no longer has them
+			Faded: resultItem.Expired && !m.cache.IsCached(resultItem),
+		})
+	}
+
+	return items
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.notice)
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case []process.Result:
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case []process.Artifact:
 		m.items = msg
-		items := []list.Item{}
-		for _, resultItem := range m.items {
-			newItem := list.Item{
-				Title: resultItem.Name,
+		m.notice = ""
+		m.list.SetTitle(title(m.items))
+		m.list, _ = m.list.Update(m.listItems())
+		m.resizeList()
+		return m, nil
+
+	case CacheChanged:
+		m.list, _ = m.list.Update(list.RefreshMsg(m.listItems()))
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if !m.list.Filtering() && key.Matches(msg, refreshKey) {
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			artifact := m.items[selected]
+			if artifact.Expired {
+				m.notice = fmt.Sprintf("%s has expired and can no longer be downloaded.", artifact.Name)
+				m.resizeList()
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return ForwardMsg{Payload: artifact, Force: true}
+			}
+		}
+
+		if !m.list.Filtering() && key.Matches(msg, inspectKey) {
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			artifact := m.items[selected]
+			if artifact.Expired && !m.cache.IsCached(artifact) {
+				m.notice = fmt.Sprintf("%s has expired and can no longer be inspected.", artifact.Name)
+				m.resizeList()
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return InspectMsg{Payload: artifact}
+			}
+		}
+
+		if !m.list.Filtering() && key.Matches(msg, downloadAllKey) {
+			available := m.cache.Downloadable(m.items)
+			if len(available) == 0 {
+				m.notice = "There are no artifacts left to download."
+				m.resizeList()
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return DownloadAllMsg{Payload: available}
 			}
-			items = append(items, newItem)
 		}
-		m.list, _ = m.list.Update(items)
-		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -54,9 +200,15 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			listMsg := listMsg.(list.Msg)
 			switch listMsg.Direction {
 			case list.Forward:
+				artifact := m.items[listMsg.Item]
+				if artifact.Expired && !m.cache.IsCached(artifact) {
+					m.notice = fmt.Sprintf("%s has expired and can no longer be downloaded.", artifact.Name)
+					m.resizeList()
+					return m, nil
+				}
 				cmd = func() tea.Msg {
 					return ForwardMsg{
-						Payload: m.items[listMsg.Item],
+						Payload: artifact,
 					}
 				}
 			case list.Back:
@@ -73,10 +225,6 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 }
 
-type item struct {
-	title, desc string
-}
-
 func (m Model) View() string {
-	return lipgloss.NewStyle().Render(m.list.View())
+	return m.list.ViewWithBar(m.notice)
 }
@@ -0,0 +1,35 @@
+package artifact
+
+import (
+	"testing"
+	"time"
+
+	"github.com/real-erik/platui/process"
+)
+
+func TestExpiredArtifactsAreFaded(t *testing.T) {
+	tests := []struct {
+		name     string
+		artifact process.Artifact
+		faded    bool
+	}{
+		{"available", process.Artifact{ID: 1, Name: "playwright-report", ExpiresAt: time.Now().Add(time.Hour)}, false},
+		{"expired", process.Artifact{ID: 2, Name: "playwright-report", Expired: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(&process.Cache{Dir: t.TempDir()})
+			m, _ = m.Update([]process.Artifact{tt.artifact})
+
+			item := m.listItems()[0]
+			if item.Faded != tt.faded {
+				t.Errorf("faded = %v, want %v", item.Faded, tt.faded)
+			}
+			// the list filters on the title, which must stay plain
+			if item.Title != tt.artifact.Name {
+				t.Errorf("title = %q, want %q", item.Title, tt.artifact.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package cacheview
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/confirm"
+	"github.com/real-erik/platui/tui/format"
+	"github.com/real-erik/platui/tui/list"
+)
+
+type Model struct {
+	list    list.Model
+	items   []process.CacheEntry
+	cache   *process.Cache
+	confirm confirm.Model
+	notice  string
+	height  int
+	width   int
+}
+
+var deleteKey = key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "delete"))
+
+func NewModel(cache *process.Cache) Model {
+	list := list.NewModel("Cache")
+	list.AddKeys(deleteKey)
+
+	return Model{
+		list:    list,
+		cache:   cache,
+		confirm: confirm.NewModel(),
+	}
+}
+
+// DeleteMsg is sent once the user confirmed deleting an artifact.
+type DeleteMsg struct {
+	Payload process.CacheEntry
+}
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+type BackMsg struct{}
+
+// ForwardMsg opens a cached artifact in the filepicker.
+type ForwardMsg struct {
+	Payload process.CacheEntry
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) title() string {
+	var total int64
+	for _, entry := range m.items {
+		total += entry.Size
+	}
+
+	title := fmt.Sprintf("Cache · %s", format.Bytes(total))
+	if m.cache.MaxSize > 0 {
+		title += " of " + format.Bytes(m.cache.MaxSize)
+	}
+
+	return title + " · " + m.cache.Dir
+}
+
+func (m Model) description(entry process.CacheEntry) string {
+	path, err := filepath.Rel(m.cache.Dir, entry.Path)
+	if err != nil {
+		path = entry.Path
+	}
+
+	return fmt.Sprintf("%s · used %s · %s", format.Bytes(entry.Size), format.Ago(entry.LastUsed), path)
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.bar())
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case []process.CacheEntry:
+		// most recently used first
+		m.items = nil
+		for i := len(msg) - 1; i >= 0; i-- {
+			m.items = append(m.items, msg[i])
+		}
+
+		items := []list.Item{}
+		for _, entry := range m.items {
+			items = append(items, list.Item{
+				Title:       entry.Name,
+				Description: m.description(entry),
+			})
+		}
+		m.list.SetTitle(m.title())
+		m.list, _ = m.list.Update(items)
+		m.resizeList()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.confirm.Active() {
+			var cmd tea.Cmd
+			m.confirm, cmd = m.confirm.Update(msg)
+			m.resizeList()
+			return m, cmd
+		}
+
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if !m.list.Filtering() && key.Matches(msg, deleteKey) {
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			entry := m.items[selected]
+			m.confirm = m.confirm.Ask(fmt.Sprintf("Delete %s (%s)?", entry.Name, format.Bytes(entry.Size)), DeleteMsg{Payload: entry})
+			m.resizeList()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	if cmd != nil {
+		listMsg := cmd()
+		switch listMsg.(type) {
+		case list.Msg:
+			listMsg := listMsg.(list.Msg)
+			switch listMsg.Direction {
+			case list.Forward:
+				cmd = func() tea.Msg {
+					return ForwardMsg{
+						Payload: m.items[listMsg.Item],
+					}
+				}
+
+			case list.Back:
+				cmd = func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		default:
+			// this is a command from bubbletea list, let it pass through
+		}
+	}
+
+	return m, cmd
+
+}
+
+func (m Model) bar() string {
+	if m.confirm.Active() {
+		return m.confirm.View()
+	}
+
+	return m.notice
+}
+
+func (m Model) View() string {
+	return m.list.ViewWithBar(m.bar())
+}
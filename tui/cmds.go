@@ -1,8 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/archive"
+	"github.com/real-erik/platui/tui/artifact"
+	"github.com/real-erik/platui/tui/dispatch"
+	"github.com/real-erik/platui/tui/download"
+	"github.com/real-erik/platui/tui/job"
+	"github.com/real-erik/platui/tui/logview"
+	"github.com/real-erik/platui/tui/place"
+	"github.com/real-erik/platui/tui/workflow"
 )
 
 type organizationDataMsg struct {
@@ -10,29 +24,154 @@ type organizationDataMsg struct {
 }
 
 type repositoryDataMsg struct {
+	Payload  []process.Result
+	NextPage int
+}
+
+// repositoryPageMsg and repositorySearchMsg belong to the load-th time the
+// repositories of organization were loaded, later ones are dropped.
+type repositoryPageMsg struct {
+	Payload      []process.Result
+	NextPage     int
+	organization string
+	load         int
+	err          error
+}
+
+type repositorySearchMsg struct {
 	Payload []process.Result
+	load    int
+	err     error
 }
 
-type workflowDataMsg struct {
+type definitionDataMsg struct {
 	Payload []process.Result
 }
 
+// workflowDataMsg and workflowPageMsg name the workflow they were loaded for,
+// so the run list never shows runs under another workflow's name.
+type workflowDataMsg struct {
+	Payload  []process.Run
+	NextPage int
+	workflow string
+}
+
+type workflowPageMsg struct {
+	Payload    []process.Run
+	NextPage   int
+	repository string
+	workflowId int64
+	workflow   string
+	filter     process.RunFilter
+	reset      bool
+	err        error
+}
+
+// jobDataMsg has the user pick a job to rerun when pick is set.
+type jobDataMsg struct {
+	Payload []process.Job
+	refresh bool
+	pick    bool
+}
+
+// actionDoneMsg and jobRerunDoneMsg tell how an action the user confirmed
+// went, successful or not.
+type actionDoneMsg struct {
+	notice string
+}
+
+type jobRerunDoneMsg struct {
+	notice string
+	runId  int64
+	picked bool
+}
+
+type logDataMsg struct {
+	Job     process.Job
+	Payload string
+}
+
 type artifactDataMsg struct {
-	Payload []process.Result
+	Payload []process.Artifact
 }
 
+// filepickerDataMsg carries an artifact ID, or a run ID when run is set.
 type filepickerDataMsg struct {
 	Payload int64
+	run     bool
+}
+
+type dispatchDataMsg struct {
+	Workflow process.Result
+	Ref      string
+}
+
+// downloadMsg starts downloading artifacts, all of them into the run's
+// directory when all is set. Cached artifacts are downloaded again when
+// force is set.
+type downloadMsg struct {
+	run       process.Run
+	artifacts []process.Artifact
+	all       bool
+	force     bool
+}
+
+type downloadProgressMsg struct {
+	Payload []download.Progress
+	updates *progressUpdates
+}
+
+type downloadCancelledMsg struct{}
+
+// loadingCancelledMsg leaves a Loading screen the user cancelled.
+type loadingCancelledMsg struct {
+	notice string
 }
 
-type errorMsg struct{ err error }
+// stateSavedMsg shows the places after a pin or a visit was saved, and tells
+// the user how pinning went or why saving failed.
+type stateSavedMsg struct {
+	places place.Places
+	notice string
+}
+
+type cacheDataMsg struct {
+	Payload []process.CacheEntry
+	refresh bool
+	notice  string
+}
+
+type archiveDataMsg struct {
+	Payload process.Archive
+}
+
+// extractedMsg carries the path a file of an archive was extracted to.
+type extractedMsg struct {
+	Payload string
+	name    string
+}
+
+type errorMsg struct {
+	err   error
+	retry tea.Cmd
+}
+
+// owner is the account the selected repository belongs to, which isn't
+// necessarily the selected organization when that is the user's own account.
+func (m model) owner() string {
+	if m.repository.Selected.Owner != "" {
+		return m.repository.Selected.Owner
+	}
+
+	return m.organization.Selected.Name
+}
 
 func (m model) getOrganizationsCmd() tea.Cmd {
 	return func() tea.Msg {
 		organizations, err := m.process.GetOrganizations()
 
 		if err != nil {
-			return errorMsg{err}
+			return errorMsg{err, m.getOrganizationsCmd()}
 		}
 
 		return organizationDataMsg{Payload: organizations}
@@ -41,49 +180,407 @@ func (m model) getOrganizationsCmd() tea.Cmd {
 
 func (m model) getRepositoriesCmd(organization string) tea.Cmd {
 	return func() tea.Msg {
-		repositories, err := m.process.GetRepositories(organization)
+		repositories, nextPage, err := m.process.GetRepositories(organization, 1)
+
+		if err != nil {
+			return errorMsg{err, m.getRepositoriesCmd(organization)}
+		}
+
+		return repositoryDataMsg{Payload: repositories, NextPage: nextPage}
+	}
+}
+
+func (m model) getMoreRepositoriesCmd(organization string, page int) tea.Cmd {
+	load := m.repositoryLoad
+	return func() tea.Msg {
+		repositories, nextPage, err := m.process.GetRepositories(organization, page)
+
+		return repositoryPageMsg{Payload: repositories, NextPage: nextPage, organization: organization, load: load, err: err}
+	}
+}
+
+func (m model) searchRepositoriesCmd(query string) tea.Cmd {
+	organization, load := m.organization.Selected.Name, m.repositoryLoad
+	return func() tea.Msg {
+		repositories, err := m.process.SearchRepositories(organization, query)
+
+		return repositorySearchMsg{Payload: repositories, load: load, err: err}
+	}
+}
+
+func (m model) getDefinitionsCmd(repository string) tea.Cmd {
+	return func() tea.Msg {
+		definitions, err := m.process.GetWorkflows(m.owner(), repository)
+
+		if err != nil {
+			return errorMsg{err, m.getDefinitionsCmd(repository)}
+		}
+
+		return definitionDataMsg{Payload: definitions}
+	}
+}
+
+func (m model) getWorkflowsCmd(workflowId int64) tea.Cmd {
+	return func() tea.Msg {
+		workflows, nextPage, err := m.process.GetWorkflowRuns(m.owner(), m.repository.Selected.Name, workflowId, m.workflow.Filter, 1)
+
+		if err != nil {
+			return errorMsg{err, m.getWorkflowsCmd(workflowId)}
+		}
+
+		return workflowDataMsg{Payload: workflows, NextPage: nextPage, workflow: m.definition.Selected.Name}
+	}
+}
+
+func (m model) getMoreWorkflowsCmd(page int) tea.Cmd {
+	return func() tea.Msg {
+		repository := m.repository.Selected.Name
+		workflowId := m.definition.Selected.ID
+		filter := m.workflow.Filter
+		workflows, nextPage, err := m.process.GetWorkflowRuns(m.owner(), repository, workflowId, filter, page)
+
+		return workflowPageMsg{Payload: workflows, NextPage: nextPage, repository: repository, workflowId: workflowId, workflow: m.definition.Selected.Name, filter: filter, err: err}
+	}
+}
+
+func (m model) refreshWorkflowsCmd() tea.Cmd {
+	return func() tea.Msg {
+		repository := m.repository.Selected.Name
+		workflowId := m.definition.Selected.ID
+		filter := m.workflow.Filter
+		workflows, nextPage, err := m.process.GetWorkflowRuns(m.owner(), repository, workflowId, filter, 1)
+
+		if err != nil {
+			return errorMsg{err, m.refreshWorkflowsCmd()}
+		}
+
+		return workflowPageMsg{Payload: workflows, NextPage: nextPage, repository: repository, workflowId: workflowId, workflow: m.definition.Selected.Name, filter: filter, reset: true}
+	}
+}
+
+func (m model) getDefaultBranchCmd(workflow process.Result) tea.Cmd {
+	return func() tea.Msg {
+		branch, err := m.process.GetDefaultBranch(m.owner(), m.repository.Selected.Name)
+
+		if err != nil {
+			return errorMsg{err, m.getDefaultBranchCmd(workflow)}
+		}
+
+		return dispatchDataMsg{Workflow: workflow, Ref: branch}
+	}
+}
+
+// getWorkflowInputsCmd and dispatchWorkflowCmd report errors in the form
+// rather than on the error screen, a wrong ref or input is easily fixed.
+// Environment inputs offer the repository's environments, or are typed in
+// when those can't be listed.
+func (m model) getWorkflowInputsCmd(msg dispatch.RefMsg) tea.Cmd {
+	return func() tea.Msg {
+		inputs, err := m.process.GetWorkflowInputs(m.owner(), m.repository.Selected.Name, msg.Workflow.Path, msg.Ref)
+		if err != nil {
+			return dispatch.Inputs{Request: msg.Request, Err: err}
+		}
+
+		inputs = slices.Clone(inputs)
+		var environments []string
+		listed := false
+		for i := range inputs {
+			if inputs[i].Type != "environment" {
+				continue
+			}
+			if !listed {
+				environments, _ = m.process.GetEnvironments(m.owner(), m.repository.Selected.Name)
+				listed = true
+			}
+			inputs[i].Options = environments
+		}
+
+		return dispatch.Inputs{Request: msg.Request, Inputs: inputs}
+	}
+}
+
+func (m model) dispatchWorkflowCmd(msg dispatch.SubmitMsg) tea.Cmd {
+	return func() tea.Msg {
+		err := m.process.DispatchWorkflow(m.owner(), m.repository.Selected.Name, msg.Workflow.ID, msg.Ref, msg.Inputs)
+
+		return dispatch.Submitted{Request: msg.Request, Workflow: msg.Workflow, Ref: msg.Ref, Err: err}
+	}
+}
+
+func (m model) getJobsCmd(runId int64, pick bool) tea.Cmd {
+	return func() tea.Msg {
+		jobs, err := m.process.GetJobs(m.owner(), m.repository.Selected.Name, runId)
+
+		if err != nil {
+			return errorMsg{err, m.getJobsCmd(runId, pick)}
+		}
+
+		return jobDataMsg{Payload: jobs, pick: pick}
+	}
+}
+
+func (m model) refreshJobsCmd(runId int64) tea.Cmd {
+	return func() tea.Msg {
+		jobs, err := m.process.GetJobs(m.owner(), m.repository.Selected.Name, runId)
 
 		if err != nil {
-			return errorMsg{err}
+			return errorMsg{err, m.refreshJobsCmd(runId)}
 		}
 
-		return repositoryDataMsg{Payload: repositories}
+		return jobDataMsg{Payload: jobs, refresh: true}
 	}
 }
 
-func (m model) getWorkflowsCmd(repository string) tea.Cmd {
+func (m model) runActionCmd(action workflow.ActionMsg) tea.Cmd {
 	return func() tea.Msg {
-		workflows, err := m.process.GetWorkflowRuns(m.organization.Selected.Name, repository)
+		organization, repository, run := m.owner(), m.repository.Selected.Name, action.Payload
 
+		var err error
+		var notice, failure string
+		switch action.Action {
+		case workflow.Rerun:
+			err = m.process.RerunWorkflowRun(organization, repository, run.ID)
+			notice = "Rerun requested for " + run.Title
+			failure = "Couldn't rerun " + run.Title
+		case workflow.RerunFailed:
+			err = m.process.RerunFailedJobs(organization, repository, run.ID)
+			notice = "Rerun of failed jobs requested for " + run.Title
+			failure = "Couldn't rerun failed jobs of " + run.Title
+		case workflow.Cancel:
+			err = m.process.CancelWorkflowRun(organization, repository, run.ID)
+			notice = "Cancellation requested for " + run.Title
+			failure = "Couldn't cancel " + run.Title
+		}
+
+		// a retry from the error screen would skip the confirmation, the
+		// user asks again from the run list instead
 		if err != nil {
-			return errorMsg{err}
+			return actionDoneMsg{notice: failure + ": " + err.Error()}
+		}
+
+		return actionDoneMsg{notice: notice}
+	}
+}
+
+func (m model) rerunJobCmd(rerun job.RerunMsg) tea.Cmd {
+	return func() tea.Msg {
+		job := rerun.Payload
+		err := m.process.RerunJob(m.owner(), m.repository.Selected.Name, job.ID)
+
+		// like runActionCmd, failures go back to the confirming screen
+		if err != nil {
+			return jobRerunDoneMsg{notice: "Couldn't rerun " + job.Name + ": " + err.Error(), runId: m.workflow.Selected.ID, picked: rerun.Picked}
+		}
+
+		return jobRerunDoneMsg{notice: "Rerun requested for " + job.Name, runId: m.workflow.Selected.ID, picked: rerun.Picked}
+	}
+}
+
+func (m model) getJobLogsCmd(job process.Job) tea.Cmd {
+	return func() tea.Msg {
+		logs, err := m.process.GetJobLogs(m.owner(), m.repository.Selected.Name, job.ID, 0)
+
+		// a running job may not have any log to show yet, tailing picks it up
+		if err != nil && (job.Status == "completed" || process.KindOf(err) != process.NotFound) {
+			return errorMsg{err, m.getJobLogsCmd(job)}
+		}
+
+		return logDataMsg{Job: job, Payload: logs}
+	}
+}
+
+// tailJobLogsCmd fetches the job and what was added to its log after offset.
+func (m model) tailJobLogsCmd(job process.Job, offset int64) tea.Cmd {
+	return func() tea.Msg {
+		current, err := m.process.GetJob(m.owner(), m.repository.Selected.Name, job.ID)
+		if err != nil {
+			return logview.Tail{Job: job, Err: err}
+		}
+
+		logs, err := m.process.GetJobLogs(m.owner(), m.repository.Selected.Name, job.ID, offset)
+		if current.Status != "completed" && process.KindOf(err) == process.NotFound {
+			err = nil
 		}
 
-		return workflowDataMsg{Payload: workflows}
+		return logview.Tail{Job: current, Content: logs, Err: err}
 	}
 }
 
 func (m model) getArtifactsCmd(workflowId int64) tea.Cmd {
 	return func() tea.Msg {
-		artifacts, err := m.process.GetArtifacts(m.organization.Selected.Name, m.repository.Selected.Name, workflowId)
+		artifacts, err := m.process.GetArtifacts(m.owner(), m.repository.Selected.Name, workflowId)
 
 		if err != nil {
-			return errorMsg{err}
+			return errorMsg{err, m.getArtifactsCmd(workflowId)}
 		}
 
 		return artifactDataMsg{Payload: artifacts}
 	}
 }
 
-func (m model) downloadArtifactCmd(artifactId int64) tea.Cmd {
+func (m model) getRunArtifactsCmd(run process.Run) tea.Cmd {
 	return func() tea.Msg {
-		err := m.process.DownloadArtifact(m.organization.Selected.Name, m.repository.Selected.Name, artifactId)
+		artifacts, err := m.process.GetArtifacts(m.owner(), m.repository.Selected.Name, run.ID)
 
 		if err != nil {
-			return errorMsg{err}
+			return errorMsg{err, m.getRunArtifactsCmd(run)}
+		}
+
+		return downloadMsg{run: run, artifacts: m.cache.Downloadable(artifacts), all: true}
+	}
+}
+
+// downloadCmd reports progress on updates and closes it when done.
+func (m model) downloadCmd(ctx context.Context, cancel context.CancelFunc, request downloadMsg, updates *progressUpdates) tea.Cmd {
+	return func() tea.Msg {
+		defer cancel()
+		defer updates.close()
+
+		report := func(artifactId int64, done int64, total int64) {
+			updates.report(download.Progress{ArtifactID: artifactId, Done: done, Total: total})
 		}
 
-		return filepickerDataMsg{Payload: artifactId}
+		var err error
+		if request.all {
+			err = m.process.DownloadArtifacts(ctx, m.owner(), m.repository.Selected.Name, request.run.ID, request.artifacts, request.force, report)
+		} else {
+			artifact := request.artifacts[0]
+			err = m.process.DownloadArtifact(ctx, m.owner(), m.repository.Selected.Name, artifact, request.force, func(done int64, total int64) {
+				report(artifact.ID, done, total)
+			})
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return downloadCancelledMsg{}
+		}
+		if err != nil {
+			retry := func() tea.Msg {
+				return request
+			}
+			return errorMsg{err, retry}
+		}
+
+		if request.all {
+			return filepickerDataMsg{Payload: request.run.ID, run: true}
+		}
+		return filepickerDataMsg{Payload: request.artifacts[0].ID}
+	}
+}
+
+func (m model) getCacheEntriesCmd() tea.Cmd {
+	return func() tea.Msg {
+		entries, err := m.cache.Entries()
+
+		if err != nil {
+			return errorMsg{err, m.getCacheEntriesCmd()}
+		}
+
+		return cacheDataMsg{Payload: entries}
+	}
+}
+
+func (m model) deleteCacheEntryCmd(entry process.CacheEntry) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.cache.Delete(entry); err != nil {
+			return errorMsg{err, m.deleteCacheEntryCmd(entry)}
+		}
+
+		entries, err := m.cache.Entries()
+		if err != nil {
+			return errorMsg{err, m.getCacheEntriesCmd()}
+		}
+
+		return cacheDataMsg{Payload: entries, refresh: true, notice: "Deleted " + entry.Name + "."}
+	}
+}
+
+// openFileCmd browses zip archives other than Playwright traces, and runs
+// every other file.
+func (m model) openFileCmd(filePath string) tea.Cmd {
+	if !strings.EqualFold(filepath.Ext(filePath), ".zip") {
+		return m.runFileCmd(filePath)
+	}
+
+	return func() tea.Msg {
+		archive, err := process.OpenArchive(filePath)
+
+		if err != nil {
+			return errorMsg{err, m.openFileCmd(filePath)}
+		}
+		if archive.Trace {
+			return m.runFileCmd(filePath)()
+		}
+
+		return archiveDataMsg{Payload: archive}
+	}
+}
+
+// inspectArtifactCmd falls back to downloading the artifact when it can't
+// be read remotely, on top of the spinner of the inspection, which is left
+// together with the download. Retrying inspects it with a new context.
+func (m model) inspectArtifactCmd(ctx context.Context, cancel context.CancelFunc, payload process.Artifact) tea.Cmd {
+	return func() tea.Msg {
+		defer cancel()
+
+		archive, err := m.process.InspectArtifact(ctx, m.owner(), m.repository.Selected.Name, payload)
+
+		if errors.Is(err, context.Canceled) {
+			return loadingCancelledMsg{notice: "Stopped reading " + payload.Name + "."}
+		}
+		if errors.Is(err, process.ErrNoRanges) {
+			return downloadMsg{artifacts: []process.Artifact{payload}}
+		}
+		if err != nil {
+			retry := func() tea.Msg {
+				return artifact.InspectMsg{Payload: payload}
+			}
+			return errorMsg{err, retry}
+		}
+
+		return archiveDataMsg{Payload: archive}
+	}
+}
+
+// extractEntryCmd extracts a file of an archive on disk.
+func (m model) extractEntryCmd(msg archive.ExtractMsg) tea.Cmd {
+	return func() tea.Msg {
+		filePath, err := process.ExtractEntry(msg.Archive, msg.Entry)
+
+		if err != nil {
+			return errorMsg{err, m.extractEntryCmd(msg)}
+		}
+
+		return extractedMsg{Payload: filePath, name: msg.Entry.Name()}
+	}
+}
+
+// fetchEntryCmd extracts a file of an artifact on GitHub, reporting its
+// progress like a download, and falls back to downloading the artifact when
+// it can't be read remotely.
+func (m model) fetchEntryCmd(ctx context.Context, cancel context.CancelFunc, msg archive.ExtractMsg, updates *progressUpdates) tea.Cmd {
+	return func() tea.Msg {
+		defer cancel()
+		defer updates.close()
+
+		artifact := msg.Archive.Artifact
+		filePath, err := m.process.ExtractArtifactEntry(ctx, m.owner(), m.repository.Selected.Name, artifact, msg.Entry, func(done int64, total int64) {
+			updates.report(download.Progress{ArtifactID: artifact.ID, Done: done, Total: total})
+		})
+
+		if errors.Is(err, context.Canceled) {
+			return downloadCancelledMsg{}
+		}
+		if errors.Is(err, process.ErrNoRanges) {
+			return downloadMsg{artifacts: []process.Artifact{artifact}}
+		}
+		if err != nil {
+			retry := func() tea.Msg {
+				return msg
+			}
+			return errorMsg{err, retry}
+		}
+
+		return extractedMsg{Payload: filePath, name: msg.Entry.Name()}
 	}
 }
 
@@ -92,10 +589,39 @@ func (m model) runFileCmd(filePath string) tea.Cmd {
 		err := m.process.Run(filePath)
 
 		if err != nil {
-			return errorMsg{err}
+			return errorMsg{err, m.runFileCmd(filePath)}
 		}
 
 		// TODO: update screen to running state?
 		return nil
 	}
 }
+
+// pinCmd toggles p in the favorites.
+func (m model) pinCmd(p place.Place) tea.Cmd {
+	return func() tea.Msg {
+		pinned, err := m.state.togglePin(p)
+
+		notice := "Unpinned " + p.Name() + "."
+		switch {
+		case err != nil:
+			notice = "Couldn't save favorites: " + err.Error()
+		case pinned:
+			notice = "Pinned " + p.Name() + "."
+		}
+
+		return stateSavedMsg{places: m.state.places(), notice: notice}
+	}
+}
+
+// visitCmd remembers p as recently visited.
+func (m model) visitCmd(p place.Place) tea.Cmd {
+	return func() tea.Msg {
+		var notice string
+		if err := m.state.visit(p); err != nil {
+			notice = "Couldn't save recent places: " + err.Error()
+		}
+
+		return stateSavedMsg{places: m.state.places(), notice: notice}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/dispatch"
+	"github.com/real-erik/platui/tui/job"
+	"github.com/real-erik/platui/tui/workflow"
+)
+
+func TestFailedActionsAreNotRetried(t *testing.T) {
+	fake := process.NewFake()
+	fake.Err = &process.Error{Kind: process.Auth, Op: "rerun workflow run", Err: errors.New("forbidden")}
+	m := NewModel(fake, &process.Cache{Dir: t.TempDir()}, &state{})
+	run := process.Run{ID: 100, Title: "Fix login redirect"}
+
+	tests := []struct {
+		name   string
+		msg    func() any
+		notice string
+	}{
+		{"rerun", func() any { return m.runActionCmd(workflow.ActionMsg{Action: workflow.Rerun, Payload: run})() }, "Couldn't rerun Fix login redirect: "},
+		{"rerun failed", func() any { return m.runActionCmd(workflow.ActionMsg{Action: workflow.RerunFailed, Payload: run})() }, "Couldn't rerun failed jobs of Fix login redirect: "},
+		{"cancel", func() any { return m.runActionCmd(workflow.ActionMsg{Action: workflow.Cancel, Payload: run})() }, "Couldn't cancel Fix login redirect: "},
+		{"rerun job", func() any { return m.rerunJobCmd(job.RerunMsg{Payload: process.Job{ID: 5000, Name: "test (1/2)"}})() }, "Couldn't rerun test (1/2): "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notice string
+			switch msg := tt.msg().(type) {
+			case actionDoneMsg:
+				notice = msg.notice
+			case jobRerunDoneMsg:
+				notice = msg.notice
+			default:
+				t.Fatalf("got %T, want a notice on the confirming screen", msg)
+			}
+			if !strings.HasPrefix(notice, tt.notice) {
+				t.Fatalf("notice = %q, want it to start with %q", notice, tt.notice)
+			}
+		})
+	}
+
+	if len(fake.Actions) != 0 {
+		t.Errorf("actions = %v, want none", fake.Actions)
+	}
+}
+
+func TestEnvironmentInputsOfferEnvironments(t *testing.T) {
+	fake := process.NewFake()
+	m := NewModel(fake, &process.Cache{Dir: t.TempDir()}, &state{})
+	m.organization.Selected = process.Result{Name: "acme"}
+	m.repository.Selected = process.Result{Name: "web"}
+
+	msg := m.getWorkflowInputsCmd(dispatch.RefMsg{Workflow: fake.Workflows["acme/web"][0], Ref: "main", Request: 3})()
+	inputs, ok := msg.(dispatch.Inputs)
+	if !ok || inputs.Err != nil || inputs.Request != 3 {
+		t.Fatalf("got %#v", msg)
+	}
+
+	for _, input := range inputs.Inputs {
+		if input.Type == "environment" && !slices.Equal(input.Options, []string{"production", "staging"}) {
+			t.Errorf("options of %s = %v, want the environments", input.Name, input.Options)
+		}
+	}
+	for _, input := range fake.Inputs[50] {
+		if input.Type == "environment" && input.Options != nil {
+			t.Errorf("the fake's inputs were changed")
+		}
+	}
+}
+
+func TestOpenFileRunsTracesAndBrowsesOtherArchives(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		wantBrowse bool
+	}{
+		{"trace.zip", []string{"trace.trace", "trace.network", "resources/a1b2.jpeg"}, false},
+		{"screenshots.zip", []string{"login.png", "checkout.png"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := process.NewFake()
+			m := NewModel(fake, &process.Cache{Dir: t.TempDir()}, &state{})
+			path := filepath.Join(t.TempDir(), tt.name)
+			writeZip(t, path, tt.files)
+
+			msg := m.openFileCmd(path)()
+			if _, browsed := msg.(archiveDataMsg); browsed != tt.wantBrowse {
+				t.Fatalf("opening %s = %T, want it browsed: %v", tt.name, msg, tt.wantBrowse)
+			}
+			if ran := slices.Contains(fake.Opened, path); ran == tt.wantBrowse {
+				t.Errorf("opened %v, want %s run: %v", fake.Opened, tt.name, !tt.wantBrowse)
+			}
+		})
+	}
+}
+
+// writeZip writes an archive of empty files to path.
+func writeZip(t *testing.T, path string, files []string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	for _, name := range files {
+		if _, err := w.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
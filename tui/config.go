@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/real-erik/platui/process"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultCacheMaxSize = 5 << 30
+	defaultCacheMaxAge  = 30 * 24 * time.Hour
+)
+
+// config is read from platui/config.yaml in the user's config directory,
+// e.g.
+//
+//	cache:
+//	  dir: ~/artifacts
+//	  max_size: 10GB
+//	  max_age: 14d
+//	  unpack: true
+type config struct {
+	Cache struct {
+		Dir     string `yaml:"dir"`
+		MaxSize string `yaml:"max_size"`
+		MaxAge  string `yaml:"max_age"`
+		Unpack  string `yaml:"unpack"`
+	} `yaml:"cache"`
+}
+
+func configPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "platui", "config.yaml")
+}
+
+func readConfig(path string) (config, error) {
+	var c config
+	if path == "" {
+		return c, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// loadCache configures the artifact cache from, in order of precedence, the
+// command line, the PLATUI_CACHE_* environment variables and the config file.
+func loadCache(args []string) (*process.Cache, error) {
+	c, err := readConfig(configPath())
+	if err != nil {
+		return nil, err
+	}
+
+	dir := c.Cache.Dir
+	maxSize := c.Cache.MaxSize
+	maxAge := c.Cache.MaxAge
+	unpack := c.Cache.Unpack
+
+	for env, value := range map[string]*string{
+		"PLATUI_CACHE_DIR":      &dir,
+		"PLATUI_CACHE_MAX_SIZE": &maxSize,
+		"PLATUI_CACHE_MAX_AGE":  &maxAge,
+		"PLATUI_CACHE_UNPACK":   &unpack,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*value = v
+		}
+	}
+
+	flags := flag.NewFlagSet("platui", flag.ContinueOnError)
+	flags.StringVar(&dir, "cache-dir", dir, "where artifacts are downloaded to (default $XDG_CACHE_HOME/platui)")
+	flags.StringVar(&maxSize, "cache-max-size", maxSize, "size of the cache before artifacts are evicted, e.g. 5GB")
+	flags.StringVar(&maxAge, "cache-max-age", maxAge, "how long unused artifacts are kept, e.g. 30d")
+	// a bool flag can be given bare, a func keeps it above the config file
+	flags.BoolFunc("cache-unpack", "unpack zip and tar archives found inside artifacts", func(value string) error {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return err
+		}
+		unpack = value
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cache := &process.Cache{
+		Dir:     process.DefaultCacheDir(),
+		MaxSize: defaultCacheMaxSize,
+		MaxAge:  defaultCacheMaxAge,
+	}
+	if dir != "" {
+		cache.Dir = expandHome(dir)
+	}
+	if maxSize != "" {
+		if cache.MaxSize, err = parseSize(maxSize); err != nil {
+			return nil, err
+		}
+	}
+	if maxAge != "" {
+		if cache.MaxAge, err = parseAge(maxAge); err != nil {
+			return nil, err
+		}
+	}
+	if unpack != "" {
+		if cache.Unpack, err = strconv.ParseBool(unpack); err != nil {
+			return nil, fmt.Errorf("invalid unpack %q", unpack)
+		}
+	}
+
+	return cache, nil
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// parseSize reads sizes like 500MB or 5GB in powers of 1024, as they are
+// shown. A size of 0 disables the limit.
+func parseSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	}
+
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return int64(n * float64(multiplier)), nil
+}
+
+// parseAge reads durations like 72h, and days like 30d. An age of 0 disables
+// the limit.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"512", 512, false},
+		{"10B", 10, false},
+		{"2KB", 2 << 10, false},
+		{"500MB", 500 << 20, false},
+		{"500mb", 500 << 20, false},
+		{" 5 GB ", 5 << 30, false},
+		{"1.5GB", 3 << 29, false},
+		{"1TB", 1 << 40, false},
+		{"", 0, true},
+		{"GB", 0, true},
+		{"-1GB", 0, true},
+		{"5 gigabytes", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSize(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"0d", 0, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"72h", 72 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"1.5d", 0, true},
+		{"-1d", 0, true},
+		{"-1h", 0, true},
+		{"a week", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAge(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAge(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoadCacheUnpack(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		want    bool
+		wantErr bool
+	}{
+		{"default", "", nil, false, false},
+		{"bare flag", "", []string{"-cache-unpack"}, true, false},
+		{"flag", "", []string{"-cache-unpack=true"}, true, false},
+		{"environment", "true", nil, true, false},
+		{"flag over environment", "true", []string{"-cache-unpack=false"}, false, false},
+		{"invalid flag", "", []string{"-cache-unpack=sometimes"}, false, true},
+		{"invalid environment", "sometimes", nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// no config file
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("PLATUI_CACHE_UNPACK", tt.env)
+
+			cache, err := loadCache(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadCache(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+			if err == nil && cache.Unpack != tt.want {
+				t.Errorf("loadCache(%q).Unpack = %v, want %v", tt.args, cache.Unpack, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package confirm
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+// Model is a yes/no prompt. Once confirmed it sends the message it was asked
+// with.
+type Model struct {
+	question string
+	action   tea.Msg
+	active   bool
+}
+
+func NewModel() Model {
+	return Model{}
+}
+
+func (m Model) Ask(question string, action tea.Msg) Model {
+	m.question = question
+	m.action = action
+	m.active = true
+	return m
+}
+
+func (m Model) Active() bool {
+	return m.active
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "Y":
+			m.active = false
+			action := m.action
+			return m, func() tea.Msg {
+				return action
+			}
+		case "n", "N", "esc":
+			m.active = false
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	if !m.active {
+		return ""
+	}
+
+	return m.question + " " + styles.HelpStyle.Render("(y/n)")
+}
@@ -0,0 +1,162 @@
+package definition
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/list"
+)
+
+type Model struct {
+	list     list.Model
+	items    []process.Result
+	notice   string
+	height   int
+	width    int
+	Selected process.Result
+}
+
+var (
+	dispatchKey = key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "run workflow"))
+	pinKey      = key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin"))
+)
+
+func NewModel() Model {
+	list := list.NewModel("Workflows")
+	list.AddKeys(dispatchKey, pinKey)
+
+	return Model{
+		list: list,
+	}
+}
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+// PinMsg asks for a workflow to be pinned to the favorites, or unpinned when
+// it already is. Pinning All pins the repository.
+type PinMsg struct {
+	Payload process.Result
+}
+
+type BackMsg struct{}
+
+type ForwardMsg struct {
+	Payload process.Result
+}
+
+// DispatchMsg asks for the workflow_dispatch form of a workflow.
+type DispatchMsg struct {
+	Payload process.Result
+}
+
+// All is the entry listing the runs of every workflow. Its ID of 0 is what
+// process.Backend.GetWorkflowRuns expects for that.
+var All = process.Result{Name: "All workflows"}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.notice)
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case []process.Result:
+		m.items = append([]process.Result{All}, msg...)
+		items := []list.Item{}
+		for _, resultItem := range m.items {
+			newItem := list.Item{
+				Title:       resultItem.Name,
+				Description: resultItem.Path,
+			}
+			if resultItem.State != "" && resultItem.State != "active" {
+				newItem.Description += " · " + resultItem.State
+			}
+			items = append(items, newItem)
+		}
+		m.notice = ""
+		m.list, _ = m.list.Update(items)
+		m.resizeList()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if m.list.Filtering() {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, pinKey):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			workflow := m.items[selected]
+			return m, func() tea.Msg {
+				return PinMsg{Payload: workflow}
+			}
+
+		case key.Matches(msg, dispatchKey):
+			selected, ok := m.list.Selected()
+			if !ok || m.items[selected].ID == All.ID {
+				return m, nil
+			}
+			workflow := m.items[selected]
+			return m, func() tea.Msg {
+				return DispatchMsg{Payload: workflow}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	if cmd != nil {
+		listMsg := cmd()
+		switch listMsg.(type) {
+		case list.Msg:
+			listMsg := listMsg.(list.Msg)
+			switch listMsg.Direction {
+			case list.Forward:
+				m.Selected = m.items[listMsg.Item]
+				cmd = func() tea.Msg {
+					return ForwardMsg{
+						Payload: m.Selected,
+					}
+				}
+
+			case list.Back:
+				cmd = func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		default:
+			// this is a command from bubbletea list, let it pass through
+		}
+	}
+
+	return m, cmd
+
+}
+
+func (m Model) View() string {
+	return m.list.ViewWithBar(m.notice)
+}
@@ -0,0 +1,538 @@
+package dispatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+type stage int
+
+const (
+	refStage stage = iota
+	loadingStage
+	formStage
+	submittingStage
+	doneStage
+)
+
+// field is the widget for one input: an index into the options for choice
+// and boolean inputs and environment inputs with environments to choose
+// from, and a text input for the others.
+type field struct {
+	input  process.Input
+	text   textinput.Model
+	option int
+}
+
+func (f field) picked() bool {
+	switch f.input.Type {
+	case "choice", "boolean":
+		return true
+	case "environment":
+		return len(f.input.Options) > 0
+	}
+
+	return false
+}
+
+func (f field) options() []string {
+	if f.input.Type == "boolean" {
+		return []string{"false", "true"}
+	}
+
+	return f.input.Options
+}
+
+func (f field) value() string {
+	if f.picked() {
+		options := f.options()
+		if len(options) == 0 {
+			return ""
+		}
+		return options[f.option]
+	}
+
+	return strings.TrimSpace(f.text.Value())
+}
+
+type Model struct {
+	workflow process.Result
+	stage    stage
+	// request numbers the inputs and dispatches asked for, answers to
+	// earlier ones are dropped
+	request int
+	ref     textinput.Model
+	fields  []field
+	focus   int
+	err     error
+	// viewport scrolls the fields and the button when they don't fit
+	viewport viewport.Model
+	height   int
+	width    int
+}
+
+func NewModel() Model {
+	ref := textinput.New()
+	ref.Prompt = "Ref: "
+	ref.Placeholder = "branch or tag"
+
+	return Model{
+		ref:      ref,
+		viewport: viewport.New(0, 0),
+	}
+}
+
+// Start opens the form for a workflow, proposing ref to run it on.
+type Start struct {
+	Workflow process.Result
+	Ref      string
+}
+
+// RefMsg asks for the inputs of the workflow as of Ref.
+type RefMsg struct {
+	Workflow process.Result
+	Ref      string
+	Request  int
+}
+
+// Inputs answers a RefMsg with the same Request.
+type Inputs struct {
+	Inputs  []process.Input
+	Request int
+	Err     error
+}
+
+// SubmitMsg asks for the workflow to be dispatched.
+type SubmitMsg struct {
+	Workflow process.Result
+	Ref      string
+	Inputs   map[string]string
+	Request  int
+}
+
+// Submitted answers a SubmitMsg with the same Request.
+type Submitted struct {
+	Workflow process.Result
+	Ref      string
+	Request  int
+	Err      error
+}
+
+type BackMsg struct{}
+
+// Awaits reports whether the form is waiting for the answer to request.
+func (m Model) Awaits(request int) bool {
+	return (m.stage == loadingStage || m.stage == submittingStage) && request == m.request
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func newField(input process.Input) field {
+	f := field{input: input}
+
+	switch {
+	case f.picked():
+		for i, option := range f.options() {
+			if option == input.Default {
+				f.option = i
+			}
+		}
+	default:
+		f.text = textinput.New()
+		f.text.Prompt = "> "
+		f.text.SetValue(input.Default)
+		if input.Type == "number" {
+			f.text.Validate = func(s string) error {
+				if s == "" || s == "-" {
+					return nil
+				}
+				_, err := strconv.ParseFloat(s, 64)
+				return err
+			}
+		}
+	}
+
+	return f
+}
+
+// setFocus moves the focus to field i, len(m.fields) being the submit
+// button.
+func (m *Model) setFocus(i int) tea.Cmd {
+	m.focus = (i + len(m.fields) + 1) % (len(m.fields) + 1)
+
+	var cmd tea.Cmd
+	for i := range m.fields {
+		if i == m.focus && m.fields[i].text.Prompt != "" {
+			cmd = m.fields[i].text.Focus()
+		} else {
+			m.fields[i].text.Blur()
+		}
+	}
+
+	return cmd
+}
+
+func (m Model) validate() (map[string]string, error) {
+	inputs := map[string]string{}
+	for _, f := range m.fields {
+		value := f.value()
+		if f.input.Required && value == "" {
+			return nil, fmt.Errorf("%s is required", f.input.Name)
+		}
+		if f.input.Type == "number" && value != "" {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("%s must be a number", f.input.Name)
+			}
+		}
+		if value != "" {
+			inputs[f.input.Name] = value
+		}
+	}
+
+	return inputs, nil
+}
+
+func (m Model) updateRef(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		ref := strings.TrimSpace(m.ref.Value())
+		if ref == "" {
+			m.err = fmt.Errorf("ref is required")
+			return m, nil
+		}
+		m.err = nil
+		m.stage = loadingStage
+		m.ref.Blur()
+		m.request++
+		workflow, request := m.workflow, m.request
+		return m, func() tea.Msg {
+			return RefMsg{Workflow: workflow, Ref: ref, Request: request}
+		}
+
+	case "esc":
+		return m, func() tea.Msg {
+			return BackMsg{}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.ref, cmd = m.ref.Update(msg)
+	return m, cmd
+}
+
+func (m Model) updateForm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	onButton := m.focus == len(m.fields)
+
+	switch msg.String() {
+	case "esc":
+		m.stage = refStage
+		m.err = nil
+		return m, m.ref.Focus()
+
+	case "tab", "down":
+		return m, m.setFocus(m.focus + 1)
+
+	case "shift+tab", "up":
+		return m, m.setFocus(m.focus - 1)
+
+	case "enter":
+		if !onButton {
+			return m, m.setFocus(m.focus + 1)
+		}
+
+		inputs, err := m.validate()
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		m.stage = submittingStage
+		m.request++
+		workflow, ref, request := m.workflow, strings.TrimSpace(m.ref.Value()), m.request
+		return m, func() tea.Msg {
+			return SubmitMsg{Workflow: workflow, Ref: ref, Inputs: inputs, Request: request}
+		}
+	}
+
+	if onButton {
+		return m, nil
+	}
+
+	f := &m.fields[m.focus]
+	if options := f.options(); f.text.Prompt == "" {
+		if len(options) == 0 {
+			return m, nil
+		}
+		switch msg.String() {
+		case "left", "h":
+			f.option = (f.option + len(options) - 1) % len(options)
+		case "right", "l", " ":
+			f.option = (f.option + 1) % len(options)
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	f.text, cmd = f.text.Update(msg)
+	return m, cmd
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.follow()
+		return m, nil
+
+	case Start:
+		m.workflow = msg.Workflow
+		m.stage = refStage
+		m.fields = nil
+		m.err = nil
+		m.ref.SetValue(msg.Ref)
+		m.ref.CursorEnd()
+		return m, m.ref.Focus()
+
+	case Inputs:
+		if !m.Awaits(msg.Request) {
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.stage = refStage
+			m.err = msg.Err
+			return m, m.ref.Focus()
+		}
+
+		m.stage = formStage
+		m.fields = nil
+		for _, input := range msg.Inputs {
+			m.fields = append(m.fields, newField(input))
+		}
+		cmd := m.setFocus(0)
+		m.viewport.GotoTop()
+		m.follow()
+		return m, cmd
+
+	case Submitted:
+		if !m.Awaits(msg.Request) {
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.stage = formStage
+			m.err = msg.Err
+			m.follow()
+			return m, nil
+		}
+
+		m.stage = doneStage
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.stage {
+		case refStage:
+			return m.updateRef(msg)
+		case formStage:
+			m, cmd := m.updateForm(msg)
+			m.follow()
+			return m, cmd
+		case loadingStage:
+			if msg.String() == "esc" {
+				m.stage = refStage
+				m.request++
+				return m, m.ref.Focus()
+			}
+		case submittingStage:
+			// GitHub may still dispatch the workflow, which the screen
+			// the user goes back to is told about
+			if msg.String() == "esc" {
+				return m, func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		case doneStage:
+			if msg.String() == "esc" || msg.String() == "enter" {
+				return m, func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	if m.stage == refStage {
+		m.ref, cmd = m.ref.Update(msg)
+	}
+	return m, cmd
+}
+
+var (
+	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#EE6FF8"))
+	buttonStyle  = lipgloss.NewStyle().Padding(0, 2).Background(lipgloss.Color("#3C3C3C"))
+)
+
+func (m Model) fieldView(i int, f field) string {
+	var s strings.Builder
+
+	label := f.input.Name
+	if f.input.Required {
+		label += " *"
+	}
+	if i == m.focus {
+		s.WriteString(focusedStyle.Render("› "+label) + "\n")
+	} else {
+		s.WriteString("  " + label + "\n")
+	}
+	if f.input.Description != "" {
+		s.WriteString("  " + styles.HelpStyle.Render(f.input.Description) + "\n")
+	}
+
+	if f.text.Prompt != "" {
+		s.WriteString("  " + f.text.View())
+		return s.String()
+	}
+
+	options := f.options()
+	if len(options) == 0 {
+		s.WriteString("  " + styles.ErrorStyle.Render("no options"))
+		return s.String()
+	}
+	value := "‹ " + options[f.option] + " ›"
+	if i == m.focus {
+		value = focusedStyle.Render(value)
+	}
+	s.WriteString("  " + value)
+
+	return s.String()
+}
+
+// form renders the fields and the button, and the lines from start up to
+// end the focused one is on.
+func (m Model) form() (form string, start int, end int) {
+	var s strings.Builder
+	lines := func() int {
+		return strings.Count(s.String(), "\n")
+	}
+
+	if len(m.fields) == 0 {
+		s.WriteString(styles.HelpStyle.Render("This workflow takes no inputs.") + "\n\n")
+	}
+	for i, f := range m.fields {
+		if i == m.focus {
+			start = lines()
+		}
+		s.WriteString(m.fieldView(i, f))
+		if i == m.focus {
+			end = lines() + 1
+		}
+		s.WriteString("\n\n")
+	}
+
+	button := buttonStyle.Render("Run workflow")
+	if m.focus == len(m.fields) {
+		button = buttonStyle.Background(lipgloss.Color("#EE6FF8")).Render("Run workflow")
+		start, end = lines(), lines()+1
+	}
+	if m.stage == submittingStage {
+		button = buttonStyle.Render("Dispatching...")
+	}
+	s.WriteString(button)
+
+	return s.String(), start, end
+}
+
+// follow fits the viewport to the form or, when it is too long, to what the
+// screen leaves for it, and scrolls it to the focused field.
+func (m *Model) follow() {
+	if m.stage != formStage && m.stage != submittingStage {
+		return
+	}
+
+	form, start, end := m.form()
+	height := strings.Count(form, "\n") + 1
+	if m.height > 0 {
+		h, v := styles.DocStyle.GetFrameSize()
+		// the header ends and the footer starts with a line break of its own
+		room := m.height - v - strings.Count(m.header(), "\n") - strings.Count(m.footer(), "\n") - 1
+		height = min(height, max(room, 1))
+		m.viewport.Width = max(m.width-h, 0)
+	}
+	m.viewport.Height = height
+	m.viewport.SetContent(form)
+
+	if end > m.viewport.YOffset+height {
+		m.viewport.SetYOffset(end - height)
+	}
+	if start < m.viewport.YOffset {
+		m.viewport.SetYOffset(start)
+	}
+}
+
+func (m Model) header() string {
+	header := styles.TitleStyle.Render("Run workflow · "+m.workflow.Name) + "\n\n"
+	switch m.stage {
+	case loadingStage, formStage, submittingStage:
+		header += "Ref: " + m.ref.Value() + "\n\n"
+	}
+
+	return header
+}
+
+func (m Model) footer() string {
+	var help string
+	switch m.stage {
+	case refStage:
+		help = "enter load inputs • esc back"
+	case loadingStage:
+		help = "esc change ref"
+	case formStage:
+		help = "tab/↑/↓ move • ←/→ change • enter next/run • esc change ref"
+	case submittingStage, doneStage:
+		help = "esc back"
+	}
+
+	var s strings.Builder
+	if m.err != nil {
+		s.WriteString("\n" + styles.ErrorStyle.Render(m.err.Error()) + "\n")
+	}
+	s.WriteString("\n" + styles.HelpStyle.Render(help))
+
+	return s.String()
+}
+
+func (m Model) View() string {
+	var s strings.Builder
+	s.WriteString(m.header())
+
+	switch m.stage {
+	case refStage:
+		s.WriteString(m.ref.View() + "\n")
+
+	case loadingStage:
+		s.WriteString("Loading inputs...\n")
+
+	case formStage, submittingStage:
+		// the fields as they are now, at the position the focus scrolled to
+		form, _, _ := m.form()
+		viewport := m.viewport
+		viewport.SetContent(form)
+		s.WriteString(viewport.View() + "\n")
+
+	case doneStage:
+		s.WriteString(fmt.Sprintf("Dispatched %s on %s. The run shows up in the run list shortly.\n", m.workflow.Name, m.ref.Value()))
+	}
+
+	s.WriteString(m.footer())
+
+	return styles.DocStyle.Render(s.String())
+}
@@ -0,0 +1,164 @@
+package dispatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/real-erik/platui/process"
+)
+
+var workflow = process.Result{ID: 50, Name: "Playwright", Path: ".github/workflows/playwright.yml"}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "shift+tab":
+		return tea.KeyMsg{Type: tea.KeyShiftTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// load submits the ref and returns the request for the inputs.
+func load(t *testing.T, m Model) (Model, RefMsg) {
+	t.Helper()
+
+	m, cmd := m.Update(key("enter"))
+	msg, ok := cmd().(RefMsg)
+	if !ok {
+		t.Fatalf("enter on the ref sent %T, want a RefMsg", cmd())
+	}
+	return m, msg
+}
+
+func TestEscWhileLoading(t *testing.T) {
+	m, _ := NewModel().Update(Start{Workflow: workflow, Ref: "main"})
+	m, request := load(t, m)
+
+	m, _ = m.Update(key("esc"))
+	if m.stage != refStage {
+		t.Fatalf("stage = %v, want the ref again", m.stage)
+	}
+
+	// the inputs arriving late don't open the form anymore
+	m, _ = m.Update(Inputs{Request: request.Request, Inputs: []process.Input{{Name: "grep", Type: "string"}}})
+	if m.stage != refStage || len(m.fields) != 0 {
+		t.Fatalf("stage = %v with %d fields, want the ref", m.stage, len(m.fields))
+	}
+
+	m, request = load(t, m)
+	m, _ = m.Update(Inputs{Request: request.Request})
+	if m.stage != formStage {
+		t.Fatalf("stage = %v, want the form", m.stage)
+	}
+}
+
+func TestEscWhileSubmitting(t *testing.T) {
+	m, _ := NewModel().Update(Start{Workflow: workflow, Ref: "main"})
+	m, request := load(t, m)
+	m, _ = m.Update(Inputs{Request: request.Request})
+
+	m, cmd := m.Update(key("enter"))
+	submit, ok := cmd().(SubmitMsg)
+	if !ok || !m.Awaits(submit.Request) {
+		t.Fatalf("enter on the button sent %T", cmd())
+	}
+
+	_, cmd = m.Update(key("esc"))
+	if cmd == nil {
+		t.Fatal("esc while dispatching did nothing")
+	}
+	if _, ok := cmd().(BackMsg); !ok {
+		t.Fatalf("esc while dispatching sent %T, want a BackMsg", cmd())
+	}
+}
+
+func TestEnvironmentInputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  process.Input
+		keys   []string
+		picked bool
+		want   string
+	}{
+		{
+			"picked from the environments",
+			process.Input{Name: "target", Type: "environment", Default: "staging", Options: []string{"production", "staging"}},
+			[]string{"l"},
+			true,
+			"production",
+		},
+		{
+			"typed without environments",
+			process.Input{Name: "target", Type: "environment"},
+			[]string{"q", "a"},
+			false,
+			"qa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := NewModel().Update(Start{Workflow: workflow, Ref: "main"})
+			m, request := load(t, m)
+			m, _ = m.Update(Inputs{Request: request.Request, Inputs: []process.Input{tt.input}})
+
+			for _, k := range tt.keys {
+				m, _ = m.Update(key(k))
+			}
+
+			if f := m.fields[0]; f.picked() != tt.picked || f.value() != tt.want {
+				t.Fatalf("picked = %v, value = %q, want %v, %q", f.picked(), f.value(), tt.picked, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormFollowsFocus(t *testing.T) {
+	var inputs []process.Input
+	for i := range 12 {
+		inputs = append(inputs, process.Input{Name: fmt.Sprintf("input-%d", i), Description: "what it is for", Type: "string"})
+	}
+
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{"first field", nil, "› input-0"},
+		{"further down", []string{"tab", "tab", "tab", "tab", "tab", "tab"}, "› input-6"},
+		{"last field", []string{"shift+tab", "shift+tab"}, "› input-11"},
+		{"button", []string{"shift+tab"}, "Run workflow"},
+		{"around again", []string{"shift+tab", "tab"}, "› input-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := NewModel().Update(Start{Workflow: workflow, Ref: "main"})
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+			m, request := load(t, m)
+			m, _ = m.Update(Inputs{Request: request.Request, Inputs: inputs})
+			for _, k := range tt.keys {
+				m, _ = m.Update(key(k))
+			}
+
+			view := m.View()
+			if !strings.Contains(view, tt.want) {
+				t.Errorf("view doesn't show %q:\n%s", tt.want, view)
+			}
+			if !strings.Contains(view, "esc change ref") {
+				t.Errorf("view doesn't show the help:\n%s", view)
+			}
+			if height := lipgloss.Height(view); height > 20 {
+				t.Errorf("view is %d lines high, want at most 20", height)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/format"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+// transfer is the progress of one artifact.
+type transfer struct {
+	artifact process.Artifact
+	done     int64
+	total    int64
+}
+
+type Model struct {
+	transfers  []transfer
+	started    time.Time
+	cancelling bool
+	progress   progress.Model
+	width      int
+}
+
+func NewModel() Model {
+	return Model{
+		progress: progress.New(progress.WithDefaultGradient()),
+	}
+}
+
+// Start resets the screen for downloading artifacts.
+type Start struct {
+	Artifacts []process.Artifact
+}
+
+// Progress reports the bytes of an artifact downloaded so far. Total is -1
+// when the server didn't say, the artifact's size is used instead.
+type Progress struct {
+	ArtifactID int64
+	Done       int64
+	Total      int64
+}
+
+// CancelMsg asks for the download to be cancelled.
+type CancelMsg struct{}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, _ := styles.DocStyle.GetFrameSize()
+		m.width = msg.Width - h
+		return m, nil
+
+	case Start:
+		m.transfers = nil
+		for _, artifact := range msg.Artifacts {
+			m.transfers = append(m.transfers, transfer{artifact: artifact, total: artifact.Size})
+		}
+		m.started = time.Now()
+		m.cancelling = false
+		return m, nil
+
+	case Progress:
+		for i := range m.transfers {
+			if m.transfers[i].artifact.ID == msg.ArtifactID {
+				m.transfers[i].done = msg.Done
+				if msg.Total > 0 {
+					m.transfers[i].total = msg.Total
+				}
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "esc" && !m.cancelling {
+			m.cancelling = true
+			return m, func() tea.Msg {
+				return CancelMsg{}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (t transfer) percent() float64 {
+	if t.total <= 0 {
+		return 0
+	}
+
+	return min(float64(t.done)/float64(t.total), 1)
+}
+
+// stats sums up all transfers, the rate being the average since the start.
+func (m Model) stats() string {
+	var done, total int64
+	for _, t := range m.transfers {
+		done += t.done
+		total += t.total
+	}
+
+	stats := []string{format.Bytes(done)}
+	if total > 0 {
+		stats[0] += " of " + format.Bytes(total)
+	}
+
+	elapsed := time.Since(m.started).Seconds()
+	if elapsed <= 0 || done == 0 {
+		return stats[0]
+	}
+
+	rate := float64(done) / elapsed
+	stats = append(stats, format.Bytes(int64(rate))+"/s")
+	if total > done {
+		eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+		stats = append(stats, eta.Round(time.Second).String()+" left")
+	}
+
+	return strings.Join(stats, " · ")
+}
+
+func (m Model) title() string {
+	if len(m.transfers) == 1 {
+		return "Downloading " + m.transfers[0].artifact.Name
+	}
+
+	return fmt.Sprintf("Downloading %d artifacts", len(m.transfers))
+}
+
+func (m Model) View() string {
+	var s strings.Builder
+	s.WriteString(styles.TitleStyle.Render(m.title()) + "\n\n")
+
+	if len(m.transfers) == 1 {
+		m.progress.Width = min(m.width, 80)
+		s.WriteString(m.progress.ViewAs(m.transfers[0].percent()) + "\n\n")
+	} else {
+		// a column of names next to a column of bars
+		nameWidth := 0
+		for _, t := range m.transfers {
+			nameWidth = max(nameWidth, lipgloss.Width(t.artifact.Name))
+		}
+		nameWidth = min(nameWidth, 30)
+		m.progress.Width = max(min(m.width-nameWidth-2, 80), 10)
+
+		for _, t := range m.transfers {
+			name := lipgloss.NewStyle().Width(nameWidth).MaxWidth(nameWidth).Render(t.artifact.Name)
+			s.WriteString(name + "  " + m.progress.ViewAs(t.percent()) + "\n")
+		}
+		s.WriteString("\n")
+	}
+	s.WriteString(m.stats() + "\n\n")
+
+	if m.cancelling {
+		s.WriteString(styles.HelpStyle.Render("cancelling..."))
+	} else {
+		s.WriteString(styles.HelpStyle.Render("esc cancel"))
+	}
+
+	return styles.DocStyle.Render(s.String())
+}
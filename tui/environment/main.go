@@ -15,7 +15,10 @@ type Model struct {
 func NewModel() Model {
 	items := []process.Result{
 		{Name: "Github"},
+		{Name: "Favorites"},
+		{Name: "Recent"},
 		{Name: "Local"},
+		{Name: "Cache"},
 	}
 
 	listItems := []list.Item{}
@@ -0,0 +1,107 @@
+package errorview
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+type Model struct {
+	err   error
+	width int
+}
+
+func NewModel() Model {
+	return Model{}
+}
+
+type BackMsg struct{}
+
+type RetryMsg struct{}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func title(kind process.ErrorKind) string {
+	switch kind {
+	case process.Auth:
+		return "Authentication failed"
+	case process.NotFound:
+		return "Not found"
+	case process.RateLimited:
+		return "Rate limited"
+	case process.Network:
+		return "Network error"
+	case process.Extraction:
+		return "Extraction failed"
+	}
+
+	return "Something went wrong"
+}
+
+func hint(kind process.ErrorKind) string {
+	switch kind {
+	case process.Auth:
+		return "Check that GITHUB_TOKEN is set and has the repo and workflow scopes."
+	case process.NotFound:
+		return "The resource may have been deleted, or the token can't see it."
+	case process.RateLimited:
+		return "Wait for the rate limit to reset and retry."
+	case process.Network:
+		return "Check your connection and retry."
+	case process.Extraction:
+		return "The downloaded archive could not be unpacked."
+	}
+
+	return ""
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case error:
+		m.err = msg
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r":
+			return m, func() tea.Msg {
+				return RetryMsg{}
+			}
+		case "esc":
+			return m, func() tea.Msg {
+				return BackMsg{}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) View() string {
+	if m.err == nil {
+		return ""
+	}
+
+	kind := process.KindOf(m.err)
+	h, _ := styles.DocStyle.GetFrameSize()
+	message := lipgloss.NewStyle().Width(max(m.width-h, 0)).Render(m.err.Error())
+
+	var s strings.Builder
+	s.WriteString(styles.ErrorTitleStyle.Render(title(kind)) + "\n\n")
+	s.WriteString(styles.ErrorStyle.Render(message) + "\n")
+	if hint := hint(kind); hint != "" {
+		s.WriteString("\n" + hint + "\n")
+	}
+	s.WriteString("\n" + styles.HelpStyle.Render("r retry • esc back"))
+
+	return styles.DocStyle.Render(s.String())
+}
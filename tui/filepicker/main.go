@@ -3,7 +3,7 @@ package filepicker
 import (
 	"errors"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -38,7 +38,8 @@ type SelectedMsg struct {
 
 type clearErrorMsg struct{}
 
-type ArtifactMsg int64
+// DirMsg roots the filepicker at a directory of downloaded artifacts.
+type DirMsg string
 
 type LocalMsg struct {}
 
@@ -48,10 +49,13 @@ func clearErrorAfter(t time.Duration) tea.Cmd {
 	})
 }
 
-func getCurrentDirectory(artifactId int64) string {
+func getCurrentDirectory(dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+
 	wd, _ := os.Getwd()
-	wd = wd + "/output/" + strconv.FormatInt(artifactId, 10)
-	return wd
+	return filepath.Join(wd, dir)
 }
 
 func (m Model) Init() tea.Cmd {
@@ -61,8 +65,8 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
-	case ArtifactMsg:
-		wd := getCurrentDirectory(int64(msg))
+	case DirMsg:
+		wd := getCurrentDirectory(string(msg))
 		m.filepicker.CurrentDirectory = wd
 		cmd := m.Init()
 		return m, cmd
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"fmt"
+	"time"
+)
+
+// Bytes humanizes a size using powers of 1024, e.g. 12.3 MB.
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Ago describes how long ago t was, falling back to the date after a month.
+func Ago(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	since := time.Since(t)
+	switch {
+	case since < time.Minute:
+		return "just now"
+	case since < 30*24*time.Hour:
+		return relative(since) + " ago"
+	}
+
+	return t.Format("2006-01-02")
+}
+
+// Until describes how long it is until t, e.g. "in 3d".
+func Until(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	until := time.Until(t)
+	if until < time.Minute {
+		return "now"
+	}
+
+	return "in " + relative(until)
+}
+
+func relative(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,218 @@
+package job
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/confirm"
+	"github.com/real-erik/platui/tui/list"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+// Model lists the jobs of a run. Picking, the user picks a job to rerun
+// instead of opening its steps.
+type Model struct {
+	list     list.Model
+	items    []process.Job
+	Selected process.Job
+	picking  bool
+	confirm  confirm.Model
+	notice   string
+	height   int
+	width    int
+}
+
+var (
+	logKey   = key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "log"))
+	rerunKey = key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "rerun job"))
+)
+
+func NewModel() Model {
+	list := list.NewModel("Jobs")
+	list.AddKeys(logKey, rerunKey)
+
+	return Model{
+		list:    list,
+		confirm: confirm.NewModel(),
+	}
+}
+
+// RerunMsg is sent once the user confirmed rerunning a job, Picked when
+// it was picked to be rerun.
+type RerunMsg struct {
+	Payload process.Job
+	Picked  bool
+}
+
+// Pick has the user pick a job to rerun from the jobs that were just shown.
+type Pick struct{}
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+type BackMsg struct{}
+
+type ForwardMsg struct {
+	Payload process.Job
+}
+
+// LogMsg asks for the log of a job.
+type LogMsg struct {
+	Payload process.Job
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func description(job process.Job) string {
+	desc := job.Runner
+	if desc == "" {
+		desc = job.Status
+	}
+	if duration := job.Duration(); duration > 0 {
+		desc += " · " + duration.Round(time.Second).String()
+	}
+
+	return desc
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.bar())
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case Pick:
+		m.picking = true
+		m.notice = "Pick the job to rerun."
+		m.resizeList()
+		return m, nil
+
+	case []process.Job:
+		m.items = msg
+		m.picking = false
+		items := []list.Item{}
+		for _, resultItem := range m.items {
+			title := styles.StatusToColor(resultItem.Status, resultItem.Conclusion) + " " + resultItem.Name
+			if resultItem.Attempt > 1 {
+				title += fmt.Sprintf(" (attempt %d)", resultItem.Attempt)
+			}
+			newItem := list.Item{
+				Title:       title,
+				Description: description(resultItem),
+			}
+			items = append(items, newItem)
+		}
+		m.list, _ = m.list.Update(items)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.confirm.Active() {
+			var cmd tea.Cmd
+			m.confirm, cmd = m.confirm.Update(msg)
+			m.resizeList()
+			return m, cmd
+		}
+
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if m.list.Filtering() {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, logKey):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			m.Selected = m.items[selected]
+			return m, func() tea.Msg {
+				return LogMsg{Payload: m.Selected}
+			}
+
+		case key.Matches(msg, rerunKey):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			m = m.askRerun(m.items[selected])
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	if cmd != nil {
+		listMsg := cmd()
+		switch listMsg.(type) {
+		case list.Msg:
+			listMsg := listMsg.(list.Msg)
+			switch listMsg.Direction {
+			case list.Forward:
+				if m.picking {
+					m = m.askRerun(m.items[listMsg.Item])
+					return m, nil
+				}
+				m.Selected = m.items[listMsg.Item]
+				cmd = func() tea.Msg {
+					return ForwardMsg{
+						Payload: m.Selected,
+					}
+				}
+
+			case list.Back:
+				m.picking = false
+				cmd = func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		default:
+			// this is a command from bubbletea list, let it pass through
+		}
+	}
+
+	return m, cmd
+
+}
+
+func (m Model) askRerun(job process.Job) Model {
+	if job.Status != "completed" {
+		m.notice = "Only completed jobs can be rerun."
+	} else {
+		m.confirm = m.confirm.Ask(fmt.Sprintf("Rerun %q?", job.Name), RerunMsg{Payload: job, Picked: m.picking})
+	}
+	m.resizeList()
+	return m
+}
+
+func (m Model) bar() string {
+	if m.confirm.Active() {
+		return m.confirm.View()
+	}
+
+	return m.notice
+}
+
+func (m Model) View() string {
+	return m.list.ViewWithBar(m.bar())
+}
@@ -1,14 +1,21 @@
 package list
 
 import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/real-erik/platui/tui/styles"
 )
 
 type Model struct {
 	title string
 	list  list.Model
+	keys  []key.Binding
 
 	height int
 	width  int
@@ -17,10 +24,34 @@ type Model struct {
 func NewModel(title string) Model {
 	var listItems []list.Item
 
-	return Model{
+	m := Model{
 		title: title,
-		list:  list.New(listItems, list.NewDefaultDelegate(), 0, 0),
 	}
+	m.list = m.newList(listItems)
+	return m
+}
+
+// newList keeps letters like f, d, b and u free for the screens by paging
+// with the arrow keys, h/l and pgup/pgdown only.
+func (m Model) newList(items []list.Item) list.Model {
+	l := list.New(items, delegate{list.NewDefaultDelegate()}, 0, 0)
+	l.Title = m.title
+	l.KeyMap.PrevPage.SetKeys("left", "h", "pgup")
+	l.KeyMap.NextPage.SetKeys("right", "l", "pgdown")
+
+	keys := m.keys
+	l.AdditionalShortHelpKeys = func() []key.Binding { return keys }
+	l.AdditionalFullHelpKeys = func() []key.Binding { return keys }
+
+	return l
+}
+
+// AddKeys shows a screen's own key bindings in the list help.
+func (m *Model) AddKeys(keys ...key.Binding) {
+	m.keys = append(m.keys, keys...)
+	all := m.keys
+	m.list.AdditionalShortHelpKeys = func() []key.Binding { return all }
+	m.list.AdditionalFullHelpKeys = func() []key.Binding { return all }
 }
 
 type Direction = int
@@ -30,9 +61,58 @@ const (
 	Back
 )
 
+// Item is a line of the list. Columns, when set, are shown instead of the
+// description. Faded items are drawn dimmed, like ones that can't be used.
 type Item struct {
 	Title       string
 	Description string
+	Columns     []Column
+	Faded       bool
+}
+
+// Column is part of an item's description, cut or padded to Width cells so
+// that columns line up from item to item.
+type Column struct {
+	Text  string
+	Width int
+}
+
+func layout(columns []Column) string {
+	var parts []string
+	for _, c := range columns {
+		text := c.Text
+		if ansi.StringWidth(text) > c.Width {
+			text = ansi.Truncate(text, c.Width, "…")
+		}
+		parts = append(parts, text+strings.Repeat(" ", max(c.Width-ansi.StringWidth(text), 0)))
+	}
+
+	return strings.TrimRight(strings.Join(parts, " "), " ")
+}
+
+// delegate draws items like the default delegate, laying their columns out
+// first. Styling happens here rather than in the titles, which the list
+// filters on.
+type delegate struct {
+	list.DefaultDelegate
+}
+
+func (d delegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if i, ok := listItem.(item); ok {
+		if len(i.columns) > 0 {
+			i.desc = layout(i.columns)
+			listItem = i
+		}
+		if i.faded {
+			faded := d.Styles.DimmedTitle.GetForeground()
+			d.Styles.NormalTitle = d.Styles.NormalTitle.Foreground(faded)
+			d.Styles.NormalDesc = d.Styles.NormalDesc.Foreground(faded)
+			d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(faded)
+			d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(faded)
+		}
+	}
+
+	d.DefaultDelegate.Render(w, m, index, listItem)
 }
 
 type Msg struct {
@@ -40,6 +120,13 @@ type Msg struct {
 	Direction Direction
 }
 
+// AppendMsg adds items to the end of the list without resetting the cursor.
+type AppendMsg []Item
+
+// RefreshMsg replaces the items of the list without resetting the cursor, for
+// when the items themselves stayed the same.
+type RefreshMsg []Item
+
 // FIXME: why doesn't this work?
 func (m Model) setListSize() {
 	h, v := styles.DocStyle.GetFrameSize()
@@ -48,13 +135,50 @@ func (m Model) setListSize() {
 
 type item struct {
 	title, desc string
+	columns     []Column
+	faded       bool
 	id          int
 }
 
+func newItem(i Item, id int) item {
+	return item{title: i.Title, desc: i.Description, columns: i.Columns, faded: i.Faded, id: id}
+}
+
 func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
 func (i item) FilterValue() string { return i.title }
 
+func (m *Model) SetTitle(title string) {
+	m.title = title
+	m.list.Title = title
+}
+
+// Filtering reports whether the user is typing a filter, in which case key
+// presses belong to the filter input.
+func (m Model) Filtering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+// FilterValue is what the user typed to filter the list, also while it is
+// still being typed.
+func (m Model) FilterValue() string {
+	return m.list.FilterValue()
+}
+
+// Selected returns the index of the selected item in the items the list was
+// last given, and false when the list is empty.
+func (m Model) Selected() (int, bool) {
+	selected, ok := m.list.SelectedItem().(item)
+	return selected.id, ok
+}
+
+// AtBottom reports whether the cursor is on the last item of an unfiltered
+// list.
+func (m Model) AtBottom() bool {
+	count := len(m.list.Items())
+	return count > 0 && m.list.FilterState() == list.Unfiltered && m.list.Index() == count-1
+}
+
 func (m Model) Init() tea.Cmd {
 	return nil
 }
@@ -64,16 +188,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case []Item:
 		items := []list.Item{}
 		for i, resultItem := range msg {
-			newItem := item{
-				title: resultItem.Title,
-				desc:  resultItem.Description,
-				id:    i,
-			}
-			items = append(items, newItem)
+			items = append(items, newItem(resultItem, i))
 		}
 
-		m.list = list.New(items, list.NewDefaultDelegate(), 0, 0)
-		m.list.Title = m.title
+		m.list = m.newList(items)
 
 		// FIXME: why doesn't this work?
 		// m.setListSize()
@@ -81,6 +199,22 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.list.SetSize(m.width-h, m.height-v)
 		return m, nil
 
+	case AppendMsg:
+		items := m.list.Items()
+		for _, resultItem := range msg {
+			items = append(items, newItem(resultItem, len(items)))
+		}
+		cmd := m.list.SetItems(items)
+		return m, cmd
+
+	case RefreshMsg:
+		items := []list.Item{}
+		for i, resultItem := range msg {
+			items = append(items, newItem(resultItem, i))
+		}
+		cmd := m.list.SetItems(items)
+		return m, cmd
+
 	case tea.WindowSizeMsg:
 		m.height = msg.Height
 		m.width = msg.Width
@@ -95,7 +229,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		switch msg.String() {
 		case "enter":
 			if m.list.FilterState() != list.Filtering {
-				selected := m.list.SelectedItem().(item)
+				selected, ok := m.list.SelectedItem().(item)
+				if !ok {
+					return m, nil
+				}
 				cmd := func() tea.Msg {
 					return Msg{
 						Item:      selected.id,
@@ -125,3 +262,29 @@ func (m Model) View() string {
 
 	return styles.DocStyle.Render(m.list.View())
 }
+
+// A bar is a line above the list, like a notice or a question for the user.
+// It takes barHeight rows, the margin above it included.
+const barHeight = 2
+
+var barStyle = lipgloss.NewStyle().Margin(1, 2, 0)
+
+// Resize fits the list into width and height, leaving room for bar unless it
+// is empty.
+func (m Model) Resize(width int, height int, bar string) Model {
+	if bar != "" {
+		height -= barHeight
+	}
+	m, _ = m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return m
+}
+
+// ViewWithBar shows bar above the list unless it is empty. The list must
+// have been resized for the same bar.
+func (m Model) ViewWithBar(bar string) string {
+	if bar == "" {
+		return lipgloss.NewStyle().Render(m.View())
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, barStyle.Render(bar), m.View())
+}
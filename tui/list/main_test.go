@@ -0,0 +1,77 @@
+package list
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestViewWithBar(t *testing.T) {
+	tests := []struct {
+		name string
+		bar  string
+	}{
+		{"without bar", ""},
+		{"with bar", "Deleted report."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel("Artifacts")
+			m, _ = m.Update([]Item{{Title: "report", Description: "1.2 MB"}})
+			m = m.Resize(80, 20, tt.bar)
+
+			view := m.ViewWithBar(tt.bar)
+			if height := lipgloss.Height(view); height > 20 {
+				t.Errorf("view is %d rows high, want at most 20", height)
+			}
+			if !strings.Contains(view, tt.bar) || !strings.Contains(view, "report") {
+				t.Errorf("view = %q, want the bar and the list", view)
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []Column
+		want    string
+	}{
+		{"padded", []Column{{"#412", 6}, {"main", 6}}, "#412   main"},
+		{"cut", []Column{{"fix-login-redirect", 8}, {"push", 4}}, "fix-log… push"},
+		{"wide characters", []Column{{"修正", 6}, {"push", 4}}, "修正   push"},
+		{"wide characters cut", []Column{{"ログイン修正", 5}, {"push", 4}}, "ログ… push"},
+		{"styled", []Column{{"\x1b[31mred\x1b[0m", 5}, {"x", 1}}, "\x1b[31mred\x1b[0m   x"},
+		{"empty columns at the end", []Column{{"#1", 3}, {"", 10}}, "#1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := layout(tt.columns); got != tt.want {
+				t.Fatalf("layout() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnsLineUp(t *testing.T) {
+	m := NewModel("Runs")
+	m, _ = m.Update([]Item{
+		{Title: "a", Columns: []Column{{"修正", 8}, {"push", 4}}},
+		{Title: "b", Columns: []Column{{"fix", 8}, {"push", 4}}},
+	})
+	m = m.Resize(80, 20, "")
+
+	var columns []int
+	for _, line := range strings.Split(ansi.Strip(m.View()), "\n") {
+		if i := strings.Index(line, "push"); i >= 0 {
+			columns = append(columns, ansi.StringWidth(line[:i]))
+		}
+	}
+	if len(columns) != 2 || columns[0] != columns[1] {
+		t.Fatalf("push starts at cells %v, want the same cell twice", columns)
+	}
+}
@@ -0,0 +1,649 @@
+package logview
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+type kind int
+
+const (
+	plain kind = iota
+	groupStart
+	groupEnd
+	errorLine
+	warningLine
+	noticeLine
+	commandLine
+	debugLine
+)
+
+type line struct {
+	timestamp string
+	text      string
+	kind      kind
+	// group is the index of the header of the group this line is in, or -1
+	group int
+}
+
+type Model struct {
+	viewport        viewport.Model
+	search          textinput.Model
+	searching       bool
+	query           string
+	matches         []int
+	job             process.Job
+	lines           []line
+	folded          map[int]bool
+	cursor          int
+	stripTimestamps bool
+	live            bool
+	generation      int
+	interval        time.Duration
+	size            int64
+	partial         string
+	tailErr         error
+	height          int
+	width           int
+	// visible are the lines that aren't folded away, rendered the lines
+	// rendered so far without their gutter, and offset the first row in view
+	visible  []int
+	rendered map[int]string
+	offset   int
+}
+
+func NewModel() Model {
+	search := textinput.New()
+	search.Prompt = "/"
+
+	return Model{
+		viewport:        viewport.New(0, 0),
+		search:          search,
+		folded:          map[int]bool{},
+		rendered:        map[int]string{},
+		stripTimestamps: true,
+	}
+}
+
+type BackMsg struct{}
+
+// Log is the full log of a job. Logs of jobs that haven't completed yet are
+// tailed until they do.
+type Log struct {
+	Job     process.Job
+	Content string
+}
+
+// TailMsg asks for the job to be fetched again, and what was added to its
+// log after the first Offset bytes.
+type TailMsg struct {
+	Job    process.Job
+	Offset int64
+}
+
+// Tail is the refreshed job and the log after the offset in answer to a
+// TailMsg.
+type Tail struct {
+	Job     process.Job
+	Content string
+	Err     error
+}
+
+type pollMsg struct {
+	generation int
+}
+
+// Logs are polled every pollInterval while they grow, and up to
+// maxPollInterval apart while they don't, each quiet poll doubling the wait.
+const (
+	pollInterval    = 3 * time.Second
+	maxPollInterval = 30 * time.Second
+)
+
+func (m Model) poll() tea.Cmd {
+	generation := m.generation
+	return tea.Tick(m.interval, func(time.Time) tea.Msg {
+		return pollMsg{generation: generation}
+	})
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z `)
+	// ##[error]message, as written to downloaded logs
+	markerPattern = regexp.MustCompile(`^##\[(\w+)\](.*)$`)
+	// ::error file=app.js,line=1::message, as echoed by workflow commands
+	commandPattern = regexp.MustCompile(`^::(\w+)(?: [^:]*)?::(.*)$`)
+)
+
+var kinds = map[string]kind{
+	"group":    groupStart,
+	"endgroup": groupEnd,
+	"error":    errorLine,
+	"warning":  warningLine,
+	"notice":   noticeLine,
+	"command":  commandLine,
+	"debug":    debugLine,
+}
+
+func parse(log string) []line {
+	return parseMore(nil, log)
+}
+
+// parseMore parses the rest of a log onto the lines parsed so far, which end
+// with a complete line.
+func parseMore(lines []line, log string) []line {
+	if len(lines) == 0 {
+		log = strings.TrimPrefix(log, "\ufeff")
+	}
+	log = strings.ReplaceAll(log, "\r\n", "\n")
+	log = strings.TrimSuffix(log, "\n")
+	if log == "" {
+		return lines
+	}
+
+	group := -1
+	if n := len(lines); n > 0 {
+		switch last := lines[n-1]; last.kind {
+		case groupStart:
+			group = n - 1
+		case groupEnd:
+			group = -1
+		default:
+			group = last.group
+		}
+	}
+	for _, raw := range strings.Split(log, "\n") {
+		l := line{group: group}
+
+		if timestamp := timestampPattern.FindString(raw); timestamp != "" {
+			l.timestamp = strings.TrimSpace(timestamp)
+			raw = raw[len(timestamp):]
+		}
+
+		l.text = raw
+		match := markerPattern.FindStringSubmatch(raw)
+		if match == nil {
+			match = commandPattern.FindStringSubmatch(raw)
+		}
+		if match != nil {
+			if k, ok := kinds[match[1]]; ok {
+				l.kind = k
+				l.text = match[2]
+			}
+		}
+
+		switch l.kind {
+		case groupStart:
+			l.group = -1
+			group = len(lines)
+		case groupEnd:
+			group = -1
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines
+}
+
+var (
+	groupStyle   = lipgloss.NewStyle().Bold(true)
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#E8C547"))
+	noticeStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5FAFFF"))
+	commandStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#1EE7CC"))
+	cursorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#EE6FF8"))
+)
+
+func (m Model) rows() []int {
+	var rows []int
+	for i, l := range m.lines {
+		if l.kind == groupEnd {
+			continue
+		}
+		if l.group >= 0 && m.folded[l.group] {
+			continue
+		}
+		rows = append(rows, i)
+	}
+
+	return rows
+}
+
+// layout works out the rows again after the lines or the folds changed.
+func (m *Model) layout() {
+	m.visible = m.rows()
+}
+
+func position(rows []int, index int) int {
+	pos, _ := slices.BinarySearch(rows, index)
+	return min(pos, len(rows)-1)
+}
+
+var prefixes = map[kind]string{
+	errorLine:   "Error: ",
+	warningLine: "Warning: ",
+	noticeLine:  "Notice: ",
+}
+
+// plainText is the line as shown, without colors.
+func (l line) plainText() string {
+	return prefixes[l.kind] + ansi.Strip(l.text)
+}
+
+func (m Model) isMatch(index int) bool {
+	_, found := slices.BinarySearch(m.matches, index)
+	return found
+}
+
+func (m Model) render(index int) string {
+	gutter := "  "
+	if m.isMatch(index) {
+		gutter = warningStyle.Render("•") + " "
+	}
+	if index == m.cursor {
+		gutter = cursorStyle.Render("›") + " "
+	}
+
+	return gutter + m.body(index)
+}
+
+// body renders a line without its gutter, once until the width, the
+// timestamps or the fold of the line change.
+func (m Model) body(index int) string {
+	if text, ok := m.rendered[index]; ok {
+		return text
+	}
+	l := m.lines[index]
+
+	text := l.text
+	switch l.kind {
+	case groupStart:
+		marker := "▾ "
+		if m.folded[index] {
+			marker = "▸ "
+		}
+		text = groupStyle.Render(marker + text)
+	case errorLine:
+		text = styles.ErrorStyle.Render(l.plainText())
+	case warningLine:
+		text = warningStyle.Render(l.plainText())
+	case noticeLine:
+		text = noticeStyle.Render(l.plainText())
+	case commandLine:
+		text = commandStyle.Render(l.plainText())
+	case debugLine:
+		text = styles.HelpStyle.Render(l.plainText())
+	}
+
+	if l.group >= 0 {
+		text = "  " + text
+	}
+	if !m.stripTimestamps && l.timestamp != "" {
+		text = styles.HelpStyle.Render(l.timestamp) + " " + text
+	}
+
+	// don't let colors from the log bleed into the next line
+	if strings.Contains(text, "\x1b[") {
+		text += "\x1b[0m"
+	}
+
+	text = ansi.Truncate(text, max(m.viewport.Width-2, 0), "…")
+	m.rendered[index] = text
+	return text
+}
+
+// refresh renders the rows in view, scrolling to keep the cursor in it.
+func (m *Model) refresh() {
+	if len(m.visible) == 0 {
+		m.viewport.SetContent("")
+		return
+	}
+
+	pos := position(m.visible, m.cursor)
+	m.cursor = m.visible[pos]
+
+	height := m.viewport.Height
+	if pos < m.offset {
+		m.offset = pos
+	} else if pos >= m.offset+height {
+		m.offset = pos - height + 1
+	}
+	m.offset = max(min(m.offset, len(m.visible)-height), 0)
+
+	window := m.visible[m.offset:min(m.offset+height, len(m.visible))]
+	rendered := make([]string, len(window))
+	for i, row := range window {
+		rendered[i] = m.render(row)
+	}
+	m.viewport.SetContent(strings.Join(rendered, "\n"))
+}
+
+func (m *Model) resize() {
+	h, v := styles.DocStyle.GetFrameSize()
+	if width := max(m.width-h, 0); width != m.viewport.Width {
+		m.viewport.Width = width
+		clear(m.rendered)
+	}
+	// title and footer, each followed or preceded by a blank line
+	m.viewport.Height = max(m.height-v-4, 1)
+	m.search.Width = max(m.width-h-2, 0)
+}
+
+func (m *Model) move(n int) {
+	if len(m.visible) == 0 {
+		return
+	}
+
+	pos := max(min(position(m.visible, m.cursor)+n, len(m.visible)-1), 0)
+	m.cursor = m.visible[pos]
+}
+
+func (m *Model) toggleFold() {
+	if len(m.lines) == 0 {
+		return
+	}
+
+	l := m.lines[m.cursor]
+	switch {
+	case l.kind == groupStart:
+		m.fold(m.cursor, !m.folded[m.cursor])
+	case l.group >= 0:
+		m.fold(l.group, true)
+		m.cursor = l.group
+	}
+	m.layout()
+}
+
+func (m *Model) foldAll(folded bool) {
+	for i, l := range m.lines {
+		if l.kind == groupStart {
+			m.fold(i, folded)
+		}
+	}
+	if folded && len(m.lines) > 0 && m.lines[m.cursor].group >= 0 {
+		m.cursor = m.lines[m.cursor].group
+	}
+	m.layout()
+}
+
+// fold folds or unfolds the group with the given header, which is rendered
+// again with its new marker.
+func (m *Model) fold(header int, folded bool) {
+	m.folded[header] = folded
+	delete(m.rendered, header)
+}
+
+// findMatches searches the lines from the given one on for the query,
+// keeping the matches before it.
+func (m *Model) findMatches(from int) {
+	pos, _ := slices.BinarySearch(m.matches, from)
+	m.matches = m.matches[:pos]
+	if m.query == "" {
+		return
+	}
+
+	query := strings.ToLower(m.query)
+	for i := from; i < len(m.lines); i++ {
+		if l := m.lines[i]; l.kind != groupEnd && strings.Contains(strings.ToLower(l.plainText()), query) {
+			m.matches = append(m.matches, i)
+		}
+	}
+}
+
+// jump moves the cursor to the next match after the cursor, or the previous
+// one before it, unfolding the group the match is in.
+func (m *Model) jump(forward bool) {
+	if len(m.matches) == 0 {
+		return
+	}
+
+	target := -1
+	if forward {
+		target = m.matches[0]
+		for _, match := range m.matches {
+			if match > m.cursor {
+				target = match
+				break
+			}
+		}
+	} else {
+		target = m.matches[len(m.matches)-1]
+		for i := len(m.matches) - 1; i >= 0; i-- {
+			if m.matches[i] < m.cursor {
+				target = m.matches[i]
+				break
+			}
+		}
+	}
+
+	if group := m.lines[target].group; group >= 0 && m.folded[group] {
+		m.fold(group, false)
+		m.layout()
+	}
+	m.cursor = target
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.searching = false
+		m.search.Blur()
+		m.query = m.search.Value()
+		m.findMatches(0)
+		m.jump(true)
+		m.refresh()
+		return m, nil
+
+	case "esc":
+		m.searching = false
+		m.search.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resize()
+		m.refresh()
+		return m, nil
+
+	case Log:
+		m.job = msg.Job
+		m.lines = parse(msg.Content)
+		m.folded = map[int]bool{}
+		m.rendered = map[int]string{}
+		m.cursor = 0
+		m.query = ""
+		m.matches = nil
+		m.tailErr = nil
+		m.live = msg.Job.Status != "completed"
+		m.generation++
+		m.interval = pollInterval
+		m.size = int64(len(msg.Content))
+		m.partial = partialLine(msg.Content)
+		m.offset = 0
+		m.layout()
+
+		if !m.live {
+			m.foldAll(true)
+			m.refresh()
+			return m, nil
+		}
+
+		m.cursor = max(len(m.lines)-1, 0)
+		m.refresh()
+		return m, m.poll()
+
+	case pollMsg:
+		if msg.generation != m.generation || !m.live {
+			return m, nil
+		}
+		job, offset := m.job, m.size
+		return m, func() tea.Msg {
+			return TailMsg{Job: job, Offset: offset}
+		}
+
+	case Tail:
+		if msg.Job.ID != m.job.ID || !m.live {
+			return m, nil
+		}
+
+		m.tailErr = msg.Err
+		if msg.Err != nil || msg.Content == "" && msg.Job.Status == m.job.Status {
+			m.interval = min(m.interval*2, maxPollInterval)
+			return m, m.poll()
+		}
+		m.interval = pollInterval
+
+		// keep following the end of the log unless the user scrolled up
+		following := len(m.visible) == 0 || m.cursor == m.visible[len(m.visible)-1]
+
+		m.job = msg.Job
+		m = m.appendLog(msg.Content)
+		if following {
+			m.cursor = max(len(m.lines)-1, 0)
+		}
+		m.refresh()
+
+		if m.job.Status == "completed" {
+			m.live = false
+			return m, nil
+		}
+		return m, m.poll()
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+
+		switch msg.String() {
+		case "up", "k":
+			m.move(-1)
+		case "down", "j":
+			m.move(1)
+		case "pgup", "ctrl+u":
+			m.move(-m.viewport.Height)
+		case "pgdown", "ctrl+d":
+			m.move(m.viewport.Height)
+		case "home", "g":
+			m.move(-len(m.lines))
+		case "end", "G":
+			m.move(len(m.lines))
+		case "enter", " ":
+			m.toggleFold()
+		case "E":
+			m.foldAll(false)
+		case "C":
+			m.foldAll(true)
+		case "t":
+			m.stripTimestamps = !m.stripTimestamps
+			clear(m.rendered)
+		case "n":
+			m.jump(true)
+		case "N":
+			m.jump(false)
+		case "/":
+			m.searching = true
+			m.search.SetValue("")
+			return m, m.search.Focus()
+		case "esc":
+			if m.query != "" {
+				m.query = ""
+				m.matches = nil
+				break
+			}
+			return m, func() tea.Msg {
+				return BackMsg{}
+			}
+		}
+
+		m.refresh()
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// partialLine is the line a log ends with when it isn't complete yet.
+func partialLine(log string) string {
+	return log[strings.LastIndex(log, "\n")+1:]
+}
+
+// appendLog adds what was added to the log, parsing the line that was cut off
+// last time once more.
+func (m Model) appendLog(content string) Model {
+	m.size += int64(len(content))
+	if content == "" {
+		return m
+	}
+
+	from := len(m.lines)
+	if m.partial != "" && from > 0 {
+		from--
+		m.lines = m.lines[:from]
+		delete(m.rendered, from)
+		content = m.partial + content
+	}
+	m.lines = parseMore(m.lines, content)
+	m.partial = partialLine(content)
+	m.findMatches(from)
+	m.layout()
+
+	return m
+}
+
+func (m Model) footer() string {
+	if m.searching {
+		return m.search.View()
+	}
+
+	help := "↑/↓ move • enter fold • E/C expand/collapse all • / search • t timestamps • esc back"
+	if m.query != "" {
+		position := 0
+		for i, match := range m.matches {
+			if match == m.cursor {
+				position = i + 1
+			}
+		}
+		help = fmt.Sprintf("%q %d/%d • n/N next/prev • esc clear", m.query, position, len(m.matches))
+	}
+
+	return styles.HelpStyle.Render(help)
+}
+
+func (m Model) View() string {
+	title := styles.TitleStyle.Render("Log · " + m.job.Name)
+	switch {
+	case m.tailErr != nil:
+		title += " " + styles.ErrorStyle.Render("● "+m.tailErr.Error())
+	case m.live:
+		title += " " + cursorStyle.Render("● live")
+	}
+	if len(m.lines) == 0 {
+		return styles.DocStyle.Render(title + "\n\n" + styles.HelpStyle.Render("This job has no log output.") + "\n\n" + m.footer())
+	}
+
+	return styles.DocStyle.Render(title + "\n\n" + m.viewport.View() + "\n\n" + m.footer())
+}
@@ -0,0 +1,216 @@
+package logview
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want []line
+	}{
+		{"empty", "", nil},
+		{"byte order mark only", "\ufeff\n", nil},
+		{
+			"timestamps",
+			"\ufeff2024-06-03T09:30:00.0000000Z Running 42 tests\r\n2024-06-03T09:30:01Z done\r\n",
+			[]line{
+				{timestamp: "2024-06-03T09:30:00.0000000Z", text: "Running 42 tests", group: -1},
+				{timestamp: "2024-06-03T09:30:01Z", text: "done", group: -1},
+			},
+		},
+		{
+			"groups",
+			"##[group]Run npx playwright test\nnpx playwright test\n##[endgroup]\nafter",
+			[]line{
+				{text: "Run npx playwright test", kind: groupStart, group: -1},
+				{text: "npx playwright test", group: 0},
+				{kind: groupEnd, group: 0},
+				{text: "after", group: -1},
+			},
+		},
+		{
+			"markers and commands",
+			"##[error]Process completed with exit code 1.\n##[warning]1 flaky test\n::notice file=app.js,line=1::Deprecated\n::debug::verbose\n##[command]/usr/bin/git version",
+			[]line{
+				{text: "Process completed with exit code 1.", kind: errorLine, group: -1},
+				{text: "1 flaky test", kind: warningLine, group: -1},
+				{text: "Deprecated", kind: noticeLine, group: -1},
+				{text: "verbose", kind: debugLine, group: -1},
+				{text: "/usr/bin/git version", kind: commandLine, group: -1},
+			},
+		},
+		{
+			"unknown markers stay plain",
+			"##[section]Starting\n::set-output name=x::y\nnot a ##[error] marker",
+			[]line{
+				{text: "##[section]Starting", group: -1},
+				{text: "::set-output name=x::y", group: -1},
+				{text: "not a ##[error] marker", group: -1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parse(tt.log)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowsHideFoldedGroups(t *testing.T) {
+	m := NewModel()
+	m.lines = parse("##[group]Setup\nstep 1\nstep 2\n##[endgroup]\n##[group]Test\nran\n##[endgroup]\nend")
+
+	tests := []struct {
+		name   string
+		folded map[int]bool
+		want   []int
+	}{
+		{"unfolded", map[int]bool{}, []int{0, 1, 2, 4, 5, 7}},
+		{"first folded", map[int]bool{0: true}, []int{0, 4, 5, 7}},
+		{"all folded", map[int]bool{0: true, 4: true}, []int{0, 4, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.folded = tt.folded
+			if got := m.rows(); !slices.Equal(got, tt.want) {
+				t.Fatalf("rows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailBacksOff(t *testing.T) {
+	job := process.Job{ID: 1, Status: "in_progress"}
+
+	tests := []struct {
+		name  string
+		tails []Tail
+		want  time.Duration
+	}{
+		{"first poll", nil, pollInterval},
+		{"unchanged", []Tail{{Job: job}}, 2 * pollInterval},
+		{"unchanged twice", []Tail{{Job: job}, {Job: job}}, 4 * pollInterval},
+		{"grown", []Tail{{Job: job}, {Job: job, Content: "b\n"}}, pollInterval},
+		{"failed", []Tail{{Job: job, Err: errors.New("timeout")}}, 2 * pollInterval},
+		{"at most", []Tail{{Job: job}, {Job: job}, {Job: job}, {Job: job}}, maxPollInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(Log{Job: job, Content: "a\n"})
+			for _, tail := range tt.tails {
+				m, _ = m.Update(tail)
+			}
+			if m.interval != tt.want {
+				t.Fatalf("interval = %v, want %v", m.interval, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailAppends(t *testing.T) {
+	job := process.Job{ID: 1, Status: "in_progress"}
+
+	tests := []struct {
+		name  string
+		log   string
+		tails []string
+	}{
+		{"lines", "a\n", []string{"b\n", "c\n"}},
+		{"cut off line", "a\nb", []string{"b\nc", "c\n"}},
+		{"cut off CRLF", "a\r", []string{"\nb\r\n"}},
+		{"into a group", "##[group]Run tests\n", []string{"ok\n##[endgroup]\n", "after\n"}},
+		{"cut off marker", "a\n##[gro", []string{"up]Run\nin\n"}},
+		{"byte order mark", "\ufeff", []string{"a\n"}},
+		{"nothing yet", "", []string{"", "a\nb\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(Log{Job: job, Content: tt.log})
+			m.query = "b"
+			m.findMatches(0)
+			whole := tt.log
+			for _, tail := range tt.tails {
+				m, _ = m.Update(Tail{Job: job, Content: tail})
+				whole += tail
+			}
+
+			if !reflect.DeepEqual(m.lines, parse(whole)) {
+				t.Errorf("lines = %+v, want %+v", m.lines, parse(whole))
+			}
+			matches := slices.Clone(m.matches)
+			if m.findMatches(0); !slices.Equal(matches, m.matches) {
+				t.Errorf("matches = %v, want %v", matches, m.matches)
+			}
+			if m.size != int64(len(whole)) {
+				t.Errorf("size = %d, want %d", m.size, len(whole))
+			}
+
+			_, cmd := m.Update(pollMsg{generation: m.generation})
+			if poll, ok := cmd().(TailMsg); !ok || poll.Offset != int64(len(whole)) {
+				t.Errorf("polling = %#v, want the log after %d bytes", cmd(), len(whole))
+			}
+		})
+	}
+}
+
+func TestRenderOnlyTheRowsInView(t *testing.T) {
+	log := "2024-01-01T00:00:00.0000000Z ##[group]Setup\nstep\n##[endgroup]\n"
+	for i := range 1000 {
+		log += fmt.Sprintf("line %d\n", i)
+	}
+	key := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want string
+	}{
+		{"folded", nil, "› ▸ Setup"},
+		{"unfolded", []tea.KeyMsg{{Type: tea.KeyEnter}}, "› ▾ Setup"},
+		{"timestamps", []tea.KeyMsg{key("t")}, "2024-01-01T00:00:00.0000000Z ▸ Setup"},
+		{"end", []tea.KeyMsg{key("G")}, "› line 999"},
+		{"search", []tea.KeyMsg{key("/"), key("line 500"), {Type: tea.KeyEnter}}, "› line 500"},
+		{"next match", []tea.KeyMsg{key("/"), key("line 5"), {Type: tea.KeyEnter}, key("n"), key("n")}, "• line 50 "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+			m, _ = m.Update(Log{Job: process.Job{ID: 1, Status: "completed"}, Content: log})
+			for _, k := range tt.keys {
+				m, _ = m.Update(k)
+			}
+
+			if view := m.viewport.View(); !strings.Contains(view, tt.want) {
+				t.Errorf("view = %q, want %q in it", view, tt.want)
+			}
+			// a few screens of rows, not the whole log
+			if len(m.rendered) > 3*m.viewport.Height {
+				t.Errorf("rendered %d rows, want at most %d", len(m.rendered), 3*m.viewport.Height)
+			}
+		})
+	}
+}
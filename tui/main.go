@@ -1,17 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"slices"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/archive"
 	"github.com/real-erik/platui/tui/artifact"
+	"github.com/real-erik/platui/tui/cacheview"
+	"github.com/real-erik/platui/tui/definition"
+	"github.com/real-erik/platui/tui/dispatch"
+	"github.com/real-erik/platui/tui/download"
 	"github.com/real-erik/platui/tui/environment"
+	"github.com/real-erik/platui/tui/errorview"
 	"github.com/real-erik/platui/tui/filepicker"
+	"github.com/real-erik/platui/tui/job"
+	"github.com/real-erik/platui/tui/logview"
 	"github.com/real-erik/platui/tui/organization"
+	"github.com/real-erik/platui/tui/place"
 	"github.com/real-erik/platui/tui/repository"
 	"github.com/real-erik/platui/tui/spinner"
+	"github.com/real-erik/platui/tui/step"
 	"github.com/real-erik/platui/tui/styles"
 	"github.com/real-erik/platui/tui/workflow"
 )
@@ -22,15 +36,25 @@ func (s modeStack) GoForward(v mode) modeStack {
 	return append(s, v)
 }
 
+// GoBack skips the Loading and Download modes, which only exist while waiting
+// for the next screen. A failed download may sit on top of the Loading mode
+// that led to it, both are skipped.
 func (s modeStack) GoBack() modeStack {
 	s = s[:len(s)-1]
-	if s[len(s)-1] == Loading {
+	for len(s) > 1 {
+		if current := s[len(s)-1]; current != Loading && current != Download {
+			break
+		}
 		s = s[:len(s)-1]
 	}
 
 	return s
 }
 
+func (s modeStack) Pop() modeStack {
+	return s[:len(s)-1]
+}
+
 func (s modeStack) GetCurrent() mode {
 	return s[len(s)-1]
 }
@@ -46,31 +70,206 @@ func (m model) GoForwardLoading(loadingMessage string) model {
 	return m
 }
 
+// startDownload shows the progress of a download, staying on the Download
+// mode when retrying a failed one.
+func (m model) startDownload(request downloadMsg) (model, tea.Cmd) {
+	if len(request.artifacts) == 0 {
+		m = m.leaveDownload()
+		m = m.notify(request.run.Title + " has no artifacts to download.")
+		return m, nil
+	}
+
+	if m.mode.GetCurrent() != Download {
+		m = m.GoForward(Download)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelDownload = cancel
+	updates := newProgressUpdates()
+	m.download, _ = m.download.Update(download.Start{Artifacts: request.artifacts})
+
+	return m, tea.Batch(m.downloadCmd(ctx, cancel, request, updates), waitForProgressCmd(updates))
+}
+
+// startFetch extracts a file of an artifact on GitHub, showing its progress
+// like a download, staying on the Download mode when retrying.
+func (m model) startFetch(request archive.ExtractMsg) (model, tea.Cmd) {
+	if m.mode.GetCurrent() != Download {
+		m = m.GoForward(Download)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelDownload = cancel
+	updates := newProgressUpdates()
+	entry := process.Artifact{ID: request.Archive.Artifact.ID, Name: request.Entry.Path, Size: request.Entry.Size}
+	m.download, _ = m.download.Update(download.Start{Artifacts: []process.Artifact{entry}})
+
+	return m, tea.Batch(m.fetchEntryCmd(ctx, cancel, request, updates), waitForProgressCmd(updates))
+}
+
+// openDir shows downloaded artifacts in the filepicker, which counts as using
+// them for the cache's eviction.
+func (m model) openDir(dir string) (model, tea.Cmd) {
+	m.cache.Touch(dir)
+
+	var cmd tea.Cmd
+	m = m.GoForward(Filepicker)
+	m.filepicker, cmd = m.filepicker.Update(filepicker.DirMsg(dir))
+	return m, cmd
+}
+
+// leaveDownload drops the Download mode and the Loading mode that may have
+// led to it.
+func (m model) leaveDownload() model {
+	if m.mode.GetCurrent() == Download {
+		m.mode = m.mode.Pop()
+	}
+	if m.mode.GetCurrent() == Loading {
+		m.mode = m.mode.Pop()
+	}
+
+	return m
+}
+
+// notify shows a notice on the screens that can start downloads or pin
+// places.
+func (m model) notify(notice string) model {
+	switch m.mode.GetCurrent() {
+	case Place:
+		m.place, _ = m.place.Update(place.Notice(notice))
+	case Organization:
+		m.organization, _ = m.organization.Update(organization.Notice(notice))
+	case Repository:
+		m.repository, _ = m.repository.Update(repository.Notice(notice))
+	case Definition:
+		m.definition, _ = m.definition.Update(definition.Notice(notice))
+	case Artifact:
+		m.artifact, _ = m.artifact.Update(artifact.Notice(notice))
+	case Archive:
+		m.archive, _ = m.archive.Update(archive.Notice(notice))
+	case Workflow:
+		m.workflow, _ = m.workflow.Update(workflow.Notice(notice))
+	}
+
+	return m
+}
+
+// repositoryPlace is where a repository of the selected organization is.
+func (m model) repositoryPlace(repository process.Result) place.Place {
+	p := place.Place{Organization: m.organization.Selected.Name, Repository: repository.Name}
+	if repository.Owner != p.Organization {
+		p.Owner = repository.Owner
+	}
+
+	return p
+}
+
+func (m model) workflowPlace(workflow process.Result) place.Place {
+	p := m.repositoryPlace(m.repository.Selected)
+	if workflow.ID != definition.All.ID {
+		p.WorkflowID, p.Workflow = workflow.ID, workflow.Name
+	}
+
+	return p
+}
+
+func (m model) runPlace(run process.Run) place.Place {
+	p := m.repositoryPlace(m.repository.Selected)
+	p.WorkflowID, p.Workflow = run.WorkflowID, run.Name
+	p.RunID, p.Run = run.ID, fmt.Sprintf("#%d %s", run.RunNumber, run.Title)
+
+	return p
+}
+
+// open goes straight to a place, selecting what the user would have selected
+// on the way there. Runs open on their artifacts, as they do from the runs.
+func (m model) open(p place.Place) (model, tea.Cmd) {
+	m.organization.Selected = process.Result{Name: p.Organization}
+	m.repository.Selected = process.Result{Name: p.Repository, Owner: p.Owner}
+	m.definition.Selected = process.Result{ID: p.WorkflowID, Name: p.Workflow}
+	m.workflow.Selected = process.Run{ID: p.RunID, WorkflowID: p.WorkflowID, Name: p.Workflow, Title: p.Run}
+	m.workflow, _ = m.workflow.Update(workflow.ClearFilter{})
+
+	var cmd, visit tea.Cmd
+	switch {
+	case p.Repository == "":
+		m = m.GoForwardLoading("Loading repositories")
+		cmd = m.getRepositoriesCmd(p.Organization)
+
+	case p.RunID != 0:
+		visit = m.visitCmd(p)
+		m = m.GoForwardLoading("Loading artifacts")
+		cmd = m.getArtifactsCmd(p.RunID)
+
+	case p.WorkflowID != 0:
+		visit = m.visitCmd(m.repositoryPlace(m.repository.Selected))
+		m = m.GoForwardLoading("Loading runs")
+		cmd = m.getWorkflowsCmd(p.WorkflowID)
+
+	default:
+		visit = m.visitCmd(p)
+		m = m.GoForwardLoading("Loading workflows")
+		cmd = m.getDefinitionsCmd(p.Repository)
+	}
+
+	startLoading := m.spinner.Init()
+	return m, tea.Batch(startLoading, cmd, visit)
+}
+
 type model struct {
 	mode           modeStack
 	loadingMessage string
-	process        process.Process
+	process        process.Backend
+	cache          *process.Cache
+	state          *state
 	spinner        spinner.Model
 	environment    environment.Model
+	place          place.Model
 	organization   organization.Model
 	repository     repository.Model
+	definition     definition.Model
+	dispatch       dispatch.Model
 	workflow       workflow.Model
+	job            job.Model
+	step           step.Model
+	logview        logview.Model
 	artifact       artifact.Model
+	download       download.Model
+	cancelDownload context.CancelFunc
+	cancelLoading  context.CancelFunc
 	filepicker     filepicker.Model
+	archive        archive.Model
+	cacheview      cacheview.Model
+	errorview      errorview.Model
+	retry          tea.Cmd
+	repositoryLoad int
 }
 
-func NewModel(process process.Process) model {
-	return model{
+func NewModel(process process.Backend, cache *process.Cache, state *state) model {
+	m := model{
 		process:      process,
+		cache:        cache,
+		state:        state,
 		mode:         modeStack{Environment},
 		spinner:      spinner.NewModel(),
 		environment:  environment.NewModel(),
+		place:        place.NewModel(),
 		organization: organization.NewModel(),
 		repository:   repository.NewModel(),
+		definition:   definition.NewModel(),
+		dispatch:     dispatch.NewModel(),
 		workflow:     workflow.NewModel(),
-		artifact:     artifact.NewModel(),
+		job:          job.NewModel(),
+		step:         step.NewModel(),
+		logview:      logview.NewModel(),
+		artifact:     artifact.NewModel(cache),
+		download:     download.NewModel(),
 		filepicker:   filepicker.NewModel(),
+		archive:      archive.NewModel(),
+		cacheview:    cacheview.NewModel(cache),
+		errorview:    errorview.NewModel(),
 	}
+	m.place, _ = m.place.Update(state.places())
+
+	return m
 }
 
 type mode int
@@ -78,11 +277,21 @@ type mode int
 const (
 	Loading mode = iota
 	Environment
+	Place
 	Organization
 	Repository
+	Definition
+	Dispatch
 	Workflow
+	Job
+	Step
+	Log
 	Artifact
+	Download
 	Filepicker
+	Archive
+	Cache
+	Error
 )
 
 func (m model) Init() tea.Cmd {
@@ -93,6 +302,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case errorMsg:
+		m = m.GoForward(Error)
+		m.errorview, _ = m.errorview.Update(msg.err)
+		m.retry = msg.retry
+		return m, nil
+
+	case errorview.RetryMsg:
+		m.mode = m.mode.Pop()
+		if m.mode.GetCurrent() == Loading {
+			return m, tea.Batch(m.spinner.Init(), m.retry)
+		}
+		return m, m.retry
+
+	case errorview.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
 	case organizationDataMsg:
 		m = m.GoForward(Organization)
 		m.organization, _ = m.organization.Update(msg.Payload)
@@ -100,12 +326,91 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case repositoryDataMsg:
 		m = m.GoForward(Repository)
-		m.repository, _ = m.repository.Update(msg.Payload)
+		m.repositoryLoad++
+		m.repository, _ = m.repository.Update(repository.Page{Items: msg.Payload, NextPage: msg.NextPage})
+		if msg.NextPage != 0 {
+			return m, m.getMoreRepositoriesCmd(m.organization.Selected.Name, msg.NextPage)
+		}
+		return m, nil
+
+	case repositoryPageMsg:
+		// the user may have left the repositories in the meantime
+		if msg.load != m.repositoryLoad || !slices.Contains(m.mode, Repository) {
+			return m, nil
+		}
+		m.repository, cmd = m.repository.Update(repository.Page{Items: msg.Payload, NextPage: msg.NextPage, Append: true, Err: msg.err})
+		// refiltering the list is for when it is shown
+		if m.mode.GetCurrent() != Repository {
+			cmd = nil
+		}
+		if msg.NextPage != 0 && msg.err == nil {
+			cmd = tea.Batch(cmd, m.getMoreRepositoriesCmd(msg.organization, msg.NextPage))
+		}
+		return m, cmd
+
+	case repository.SearchMsg:
+		return m, m.searchRepositoriesCmd(msg.Query)
+
+	case repositorySearchMsg:
+		if msg.load != m.repositoryLoad {
+			return m, nil
+		}
+		m.repository, cmd = m.repository.Update(repository.Found{Items: msg.Payload, Err: msg.err})
+		return m, cmd
+
+	case definitionDataMsg:
+		m = m.GoForward(Definition)
+		m.definition, _ = m.definition.Update(msg.Payload)
 		return m, nil
 
 	case workflowDataMsg:
 		m = m.GoForward(Workflow)
-		m.workflow, _ = m.workflow.Update(msg.Payload)
+		m.workflow, _ = m.workflow.Update(workflow.Page{Items: msg.Payload, NextPage: msg.NextPage, Workflow: msg.workflow})
+		return m, nil
+
+	case workflowPageMsg:
+		// the user may have left for another workflow or filter in the meantime
+		if msg.repository != m.repository.Selected.Name || msg.workflowId != m.definition.Selected.ID || msg.filter != m.workflow.Filter {
+			return m, nil
+		}
+		m.workflow, _ = m.workflow.Update(workflow.Page{Items: msg.Payload, NextPage: msg.NextPage, Append: !msg.reset, Workflow: msg.workflow, Err: msg.err})
+		return m, nil
+
+	case workflow.FilterMsg:
+		return m, m.refreshWorkflowsCmd()
+
+	case workflow.LoadMoreMsg:
+		return m, m.getMoreWorkflowsCmd(msg.Page)
+
+	case jobDataMsg:
+		if !msg.refresh {
+			m = m.GoForward(Job)
+		}
+		m.job, _ = m.job.Update(msg.Payload)
+		if msg.pick {
+			m.job, _ = m.job.Update(job.Pick{})
+		}
+		return m, nil
+
+	case actionDoneMsg:
+		m.workflow, _ = m.workflow.Update(workflow.Notice(msg.notice))
+		return m, m.refreshWorkflowsCmd()
+
+	case jobRerunDoneMsg:
+		// a job picked from the runs is rerun from there
+		if msg.picked {
+			if m.mode.GetCurrent() == Job {
+				m.mode = m.mode.GoBack()
+			}
+			m.workflow, _ = m.workflow.Update(workflow.Notice(msg.notice))
+			return m, m.refreshWorkflowsCmd()
+		}
+		m.job, _ = m.job.Update(job.Notice(msg.notice))
+		return m, m.refreshJobsCmd(msg.runId)
+
+	case logDataMsg:
+		m = m.GoForward(Log)
+		m.logview, _ = m.logview.Update(logview.Log{Job: msg.Job, Content: msg.Payload})
 		return m, nil
 
 	case artifactDataMsg:
@@ -114,9 +419,33 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case filepickerDataMsg:
-		m = m.GoForward(Filepicker)
-		m.filepicker, cmd = m.filepicker.Update(filepicker.ArtifactMsg(msg.Payload))
-		return m, cmd
+		m = m.leaveDownload()
+		m.artifact, _ = m.artifact.Update(artifact.CacheChanged{})
+		dir := m.cache.ArtifactDir(msg.Payload)
+		if msg.run {
+			dir = m.cache.RunDir(msg.Payload)
+		}
+		return m.openDir(dir)
+
+	case cacheDataMsg:
+		if !msg.refresh {
+			m = m.GoForward(Cache)
+		}
+		m.cacheview, _ = m.cacheview.Update(msg.Payload)
+		if msg.notice != "" {
+			m.cacheview, _ = m.cacheview.Update(cacheview.Notice(msg.notice))
+		}
+		return m, nil
+
+	case cacheview.ForwardMsg:
+		return m.openDir(msg.Payload.Path)
+
+	case cacheview.DeleteMsg:
+		return m, m.deleteCacheEntryCmd(msg.Payload)
+
+	case cacheview.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
 
 	case environment.ForwardMsg:
 		switch msg.Payload.Name {
@@ -126,12 +455,57 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmd := m.getOrganizationsCmd()
 			return m, tea.Batch(startLoading, cmd)
 
+		case "Favorites":
+			m = m.GoForward(Place)
+			m.place, _ = m.place.Update(place.Show(place.Favorites))
+			return m, nil
+
+		case "Recent":
+			m = m.GoForward(Place)
+			m.place, _ = m.place.Update(place.Show(place.Recent))
+			return m, nil
+
 		case "Local":
 			m = m.GoForward(Filepicker)
 			m.filepicker, cmd = m.filepicker.Update(filepicker.LocalMsg{})
 			return m, cmd
+
+		case "Cache":
+			m = m.GoForwardLoading("Reading cache")
+			startLoading := m.spinner.Init()
+			cmd := m.getCacheEntriesCmd()
+			return m, tea.Batch(startLoading, cmd)
 		}
 
+	case place.ForwardMsg:
+		return m.open(msg.Payload)
+
+	case stateSavedMsg:
+		m.place, cmd = m.place.Update(msg.places)
+		if msg.notice != "" {
+			m = m.notify(msg.notice)
+		}
+		return m, cmd
+
+	case place.PinMsg:
+		return m, m.pinCmd(msg.Payload)
+
+	case place.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
+	case organization.PinMsg:
+		return m, m.pinCmd(place.Place{Organization: msg.Payload.Name})
+
+	case repository.PinMsg:
+		return m, m.pinCmd(m.repositoryPlace(msg.Payload))
+
+	case definition.PinMsg:
+		return m, m.pinCmd(m.workflowPlace(msg.Payload))
+
+	case workflow.PinMsg:
+		return m, m.pinCmd(m.runPlace(msg.Payload))
+
 	case organization.ForwardMsg:
 		m = m.GoForwardLoading("Loading repositories")
 		startLoading := m.spinner.Init()
@@ -143,56 +517,244 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case repository.ForwardMsg:
+		visit := m.visitCmd(m.repositoryPlace(msg.Payload))
 		m = m.GoForwardLoading("Loading workflows")
-		cmd = m.getWorkflowsCmd(msg.Payload.Name)
+		cmd = m.getDefinitionsCmd(msg.Payload.Name)
 		startLoading := m.spinner.Init()
-		return m, tea.Batch(startLoading, cmd)
+		return m, tea.Batch(startLoading, cmd, visit)
 
 	case repository.BackMsg:
 		m.mode = m.mode.GoBack()
 		return m, nil
 
+	case definition.ForwardMsg:
+		m.workflow, _ = m.workflow.Update(workflow.ClearFilter{})
+		m = m.GoForwardLoading("Loading runs")
+		cmd = m.getWorkflowsCmd(msg.Payload.ID)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd)
+
+	case definition.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
+	case definition.DispatchMsg:
+		m = m.GoForwardLoading("Loading default branch")
+		cmd = m.getDefaultBranchCmd(msg.Payload)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd)
+
+	case dispatchDataMsg:
+		m = m.GoForward(Dispatch)
+		m.dispatch, cmd = m.dispatch.Update(dispatch.Start{Workflow: msg.Workflow, Ref: msg.Ref})
+		return m, cmd
+
+	case dispatch.RefMsg:
+		return m, m.getWorkflowInputsCmd(msg)
+
+	case dispatch.SubmitMsg:
+		return m, m.dispatchWorkflowCmd(msg)
+
+	case dispatch.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
+	case dispatch.Submitted:
+		if m.mode.GetCurrent() == Dispatch && m.dispatch.Awaits(msg.Request) {
+			m.dispatch, cmd = m.dispatch.Update(msg)
+			return m, cmd
+		}
+
+		// the user left the form before GitHub answered
+		if msg.Err != nil {
+			return m.notify("Couldn't dispatch " + msg.Workflow.Name + ": " + msg.Err.Error()), nil
+		}
+		return m.notify("Dispatched " + msg.Workflow.Name + " on " + msg.Ref + "."), nil
+
 	case workflow.ForwardMsg:
+		visit := m.visitCmd(m.runPlace(msg.Payload))
 		m = m.GoForwardLoading("Loading artifacts")
 		cmd = m.getArtifactsCmd(msg.Payload.ID)
 		startLoading := m.spinner.Init()
-		return m, tea.Batch(startLoading, cmd)
+		return m, tea.Batch(startLoading, cmd, visit)
 
 	case workflow.BackMsg:
 		m.mode = m.mode.GoBack()
 		return m, nil
 
+	case workflow.ActionMsg:
+		return m, m.runActionCmd(msg)
+
+	case job.RerunMsg:
+		return m, m.rerunJobCmd(msg)
+
+	case workflow.RerunJobMsg:
+		m = m.GoForwardLoading("Loading jobs")
+		cmd = m.getJobsCmd(msg.Payload.ID, true)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd)
+
+	case workflow.JobsMsg:
+		visit := m.visitCmd(m.runPlace(msg.Payload))
+		m = m.GoForwardLoading("Loading jobs")
+		cmd = m.getJobsCmd(msg.Payload.ID, false)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd, visit)
+
+	case job.ForwardMsg:
+		m = m.GoForward(Step)
+		m.step, _ = m.step.Update(msg.Payload)
+		return m, nil
+
+	case job.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
+	case step.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
+	case job.LogMsg:
+		m = m.GoForwardLoading("Loading log")
+		cmd = m.getJobLogsCmd(msg.Payload)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd)
+
+	case step.LogMsg:
+		m = m.GoForwardLoading("Loading log")
+		cmd = m.getJobLogsCmd(msg.Payload)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd)
+
+	case logview.TailMsg:
+		return m, m.tailJobLogsCmd(msg.Job, msg.Offset)
+
+	case logview.Tail:
+		m.logview, cmd = m.logview.Update(msg)
+		return m, cmd
+
+	case logview.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
 	case artifact.ForwardMsg:
-		m = m.GoForwardLoading("Downloading files")
-		cmd = m.downloadArtifactCmd(msg.Payload.ID)
+		if !msg.Force && m.cache.IsCached(msg.Payload) {
+			return m.openDir(m.cache.ArtifactDir(msg.Payload.ID))
+		}
+		return m.startDownload(downloadMsg{artifacts: []process.Artifact{msg.Payload}, force: msg.Force})
+
+	case artifact.InspectMsg:
+		if m.cache.IsCached(msg.Payload) {
+			return m.openDir(m.cache.ArtifactDir(msg.Payload.ID))
+		}
+		if m.mode.GetCurrent() != Loading {
+			m = m.GoForwardLoading("Reading " + msg.Payload.Name)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancelLoading = cancel
+		cmd = m.inspectArtifactCmd(ctx, cancel, msg.Payload)
 		startLoading := m.spinner.Init()
 		return m, tea.Batch(startLoading, cmd)
 
+	case artifact.DownloadAllMsg:
+		return m.startDownload(downloadMsg{run: m.workflow.Selected, artifacts: msg.Payload, all: true})
+
+	case workflow.DownloadAllMsg:
+		m = m.GoForwardLoading("Loading artifacts")
+		cmd = m.getRunArtifactsCmd(msg.Payload)
+		startLoading := m.spinner.Init()
+		return m, tea.Batch(startLoading, cmd)
+
+	case downloadMsg:
+		return m.startDownload(msg)
+
+	case downloadProgressMsg:
+		for _, progress := range msg.Payload {
+			m.download, _ = m.download.Update(progress)
+		}
+		return m, waitForProgressCmd(msg.updates)
+
+	case download.CancelMsg:
+		if m.cancelDownload != nil {
+			m.cancelDownload()
+		}
+		return m, nil
+
+	case downloadCancelledMsg:
+		m = m.leaveDownload()
+		m = m.notify("Download cancelled.")
+		return m, nil
+
 	case artifact.BackMsg:
 		m.mode = m.mode.GoBack()
 		return m, nil
 
 	case filepicker.SelectedMsg:
 		// TODO: change mode?
-		cmd = m.runFileCmd(msg.Payload)
+		cmd = m.openFileCmd(msg.Payload)
 		return m, cmd
 
 	case filepicker.BackMsg:
 		m.mode = m.mode.GoBack()
 		return m, nil
 
+	case archiveDataMsg:
+		if m.mode.GetCurrent() != Archive {
+			m = m.GoForward(Archive)
+		}
+		m.archive, _ = m.archive.Update(msg.Payload)
+		return m, nil
+
+	case archive.ExtractMsg:
+		if msg.Archive.Remote() {
+			return m.startFetch(msg)
+		}
+		return m, m.extractEntryCmd(msg)
+
+	case extractedMsg:
+		m = m.leaveDownload()
+		m.archive, _ = m.archive.Update(archive.Notice("Opening " + msg.name + "..."))
+		return m, m.openFileCmd(msg.Payload)
+
+	case archive.BackMsg:
+		m.mode = m.mode.GoBack()
+		return m, nil
+
+	case loadingCancelledMsg:
+		if m.mode.GetCurrent() == Loading {
+			m.mode = m.mode.Pop()
+		}
+		m = m.notify(msg.notice)
+		return m, nil
+
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
 			return m, tea.Quit
 		}
+		// only some loads can be cancelled, the others finish on their own
+		if msg.String() == "esc" && m.mode.GetCurrent() == Loading && m.cancelLoading != nil {
+			m.cancelLoading()
+			m.cancelLoading = nil
+			return m, nil
+		}
 
 	case tea.WindowSizeMsg:
 		m.environment, _ = m.environment.Update(msg)
+		m.place, _ = m.place.Update(msg)
 		m.organization, _ = m.organization.Update(msg)
 		m.repository, _ = m.repository.Update(msg)
+		m.definition, _ = m.definition.Update(msg)
+		m.dispatch, _ = m.dispatch.Update(msg)
 		m.workflow, _ = m.workflow.Update(msg)
+		m.job, _ = m.job.Update(msg)
+		m.step, _ = m.step.Update(msg)
+		m.logview, _ = m.logview.Update(msg)
 		m.artifact, _ = m.artifact.Update(msg)
+		m.download, _ = m.download.Update(msg)
 		m.filepicker, _ = m.filepicker.Update(msg)
+		m.archive, _ = m.archive.Update(msg)
+		m.cacheview, _ = m.cacheview.Update(msg)
+		m.errorview, _ = m.errorview.Update(msg)
 
 		return m, nil
 	}
@@ -203,16 +765,36 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 	case Environment:
 		m.environment, cmd = m.environment.Update(msg)
+	case Place:
+		m.place, cmd = m.place.Update(msg)
 	case Organization:
 		m.organization, cmd = m.organization.Update(msg)
 	case Repository:
 		m.repository, cmd = m.repository.Update(msg)
+	case Definition:
+		m.definition, cmd = m.definition.Update(msg)
+	case Dispatch:
+		m.dispatch, cmd = m.dispatch.Update(msg)
 	case Workflow:
 		m.workflow, cmd = m.workflow.Update(msg)
+	case Job:
+		m.job, cmd = m.job.Update(msg)
+	case Step:
+		m.step, cmd = m.step.Update(msg)
+	case Log:
+		m.logview, cmd = m.logview.Update(msg)
 	case Artifact:
 		m.artifact, cmd = m.artifact.Update(msg)
+	case Download:
+		m.download, cmd = m.download.Update(msg)
 	case Filepicker:
 		m.filepicker, cmd = m.filepicker.Update(msg)
+	case Archive:
+		m.archive, cmd = m.archive.Update(msg)
+	case Cache:
+		m.cacheview, cmd = m.cacheview.Update(msg)
+	case Error:
+		m.errorview, cmd = m.errorview.Update(msg)
 	}
 
 	return m, cmd
@@ -225,26 +807,87 @@ func (m model) View() string {
 		return styles.DocStyle.Render(m.spinner.View() + " " + m.loadingMessage + "...")
 	case Environment:
 		return m.environment.View()
+	case Place:
+		return m.place.View()
 	case Organization:
 		return m.organization.View()
 	case Repository:
 		return m.repository.View()
+	case Definition:
+		return m.definition.View()
+	case Dispatch:
+		return m.dispatch.View()
 	case Workflow:
 		return m.workflow.View()
+	case Job:
+		return m.job.View()
+	case Step:
+		return m.step.View()
+	case Log:
+		return m.logview.View()
 	case Artifact:
 		return m.artifact.View()
+	case Download:
+		return m.download.View()
 	case Filepicker:
 		return m.filepicker.View()
+	case Archive:
+		return m.archive.View()
+	case Cache:
+		return m.cacheview.View()
+	case Error:
+		return m.errorview.View()
 	}
 
 	return ""
 }
 
 func main() {
-	p := process.NewProcess(os.Getenv("GITHUB_TOKEN"))
+	cache, err := loadCache(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-	t := tea.NewProgram(NewModel(p), tea.WithAltScreen())
-	if _, err := t.Run(); err != nil {
+	var backend process.Backend
+	path := statePath()
+	demoDir := ""
+	if os.Getenv("PLATUI_DEMO") != "" {
+		// the demo's artifacts are made up, they stay out of the user's cache
+		// and its limits
+		demoDir, err = os.MkdirTemp("", "platui-demo-")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		cache = &process.Cache{Dir: demoDir, MaxSize: cache.MaxSize, MaxAge: cache.MaxAge, Unpack: cache.Unpack}
+
+		fake := process.NewFake()
+		fake.Cache = cache
+		backend = fake
+		// the demo's places don't exist on GitHub, they're only kept in memory
+		path = ""
+	} else {
+		p := process.NewProcess(os.Getenv("GITHUB_TOKEN"), cache)
+		backend = &p
+	}
+
+	state, err := loadState(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	t := tea.NewProgram(NewModel(backend, cache, state), tea.WithAltScreen())
+	_, err = t.Run()
+	process.RemoveScratch()
+	if demoDir != "" {
+		os.RemoveAll(demoDir)
+	}
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
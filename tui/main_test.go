@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/artifact"
+	"github.com/real-erik/platui/tui/errorview"
+	"github.com/real-erik/platui/tui/job"
+	"github.com/real-erik/platui/tui/place"
+	"github.com/real-erik/platui/tui/workflow"
+)
+
+// update feeds msgs to m without running the commands they return.
+func update(m model, msgs ...any) model {
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+
+	return m
+}
+
+func TestGoBack(t *testing.T) {
+	tests := []struct {
+		name  string
+		stack modeStack
+		want  modeStack
+	}{
+		{"screen", modeStack{Environment, Workflow, Artifact}, modeStack{Environment, Workflow}},
+		{"after loading", modeStack{Environment, Workflow, Loading, Artifact}, modeStack{Environment, Workflow}},
+		{"failed download", modeStack{Environment, Artifact, Download, Error}, modeStack{Environment, Artifact}},
+		{"failed download after loading", modeStack{Environment, Workflow, Loading, Download, Error}, modeStack{Environment, Workflow}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.stack.GoBack(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: GoBack() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBackFromFailedDownloadAll(t *testing.T) {
+	fake := process.NewFake()
+	m := NewModel(fake, &process.Cache{Dir: t.TempDir()}, &state{})
+	m.mode = modeStack{Environment, Workflow}
+	run := fake.WorkflowRuns["acme/web"][0]
+
+	m = update(m,
+		workflow.DownloadAllMsg{Payload: run},
+		downloadMsg{run: run, artifacts: fake.Artifacts[run.ID], all: true},
+		errorMsg{errors.New("connection reset"), nil},
+	)
+	if want := (modeStack{Environment, Workflow, Loading, Download, Error}); !slices.Equal(m.mode, want) {
+		t.Fatalf("modes after the download failed = %v, want %v", m.mode, want)
+	}
+
+	m = update(m, errorview.BackMsg{})
+	if want := (modeStack{Environment, Workflow}); !slices.Equal(m.mode, want) {
+		t.Errorf("modes after leaving the error = %v, want %v", m.mode, want)
+	}
+}
+
+func TestBackFromFailedFallbackDownload(t *testing.T) {
+	fake := process.NewFake()
+	fake.NoRanges = true
+	fake.Cache = &process.Cache{Dir: t.TempDir()}
+	m := NewModel(fake, fake.Cache, &state{})
+	m.organization.Selected = process.Result{Name: "acme"}
+	m.repository.Selected = process.Result{Name: "web"}
+	m.mode = modeStack{Environment, Artifact}
+	payload := fake.Artifacts[100][0]
+
+	m = update(m, artifact.InspectMsg{Payload: payload})
+	fallback := m.inspectArtifactCmd(context.Background(), func() {}, payload)()
+	if _, ok := fallback.(downloadMsg); !ok {
+		t.Fatalf("inspecting without range requests = %T, want a download", fallback)
+	}
+
+	m = update(m, fallback, errorMsg{errors.New("connection reset"), nil}, errorview.BackMsg{})
+	if want := (modeStack{Environment, Artifact}); !slices.Equal(m.mode, want) {
+		t.Errorf("modes after leaving the error = %v, want %v", m.mode, want)
+	}
+}
+
+func TestSavingStateReportsHowItWent(t *testing.T) {
+	dir := t.TempDir()
+	// a file where the state's directory should be
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	acme := place.Place{Organization: "acme"}
+
+	tests := []struct {
+		name   string
+		path   string
+		cmd    func(m model) tea.Cmd
+		notice string
+		pinned int
+	}{
+		{"pin", filepath.Join(dir, "state.json"), func(m model) tea.Cmd { return m.pinCmd(acme) }, "Pinned acme.", 1},
+		{"visit", filepath.Join(dir, "visited.json"), func(m model) tea.Cmd { return m.visitCmd(acme) }, "", 0},
+		{"pin fails", filepath.Join(blocked, "state.json"), func(m model) tea.Cmd { return m.pinCmd(acme) }, "Couldn't save favorites: ", 1},
+		{"visit fails", filepath.Join(blocked, "state.json"), func(m model) tea.Cmd { return m.visitCmd(acme) }, "Couldn't save recent places: ", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel(process.NewFake(), &process.Cache{Dir: t.TempDir()}, &state{path: tt.path})
+
+			msg, ok := tt.cmd(m)().(stateSavedMsg)
+			if !ok {
+				t.Fatalf("got %T, want the saved places", msg)
+			}
+			if !strings.HasPrefix(msg.notice, tt.notice) || (tt.notice == "") != (msg.notice == "") {
+				t.Errorf("notice = %q, want %q", msg.notice, tt.notice)
+			}
+			if len(msg.places.Favorites) != tt.pinned {
+				t.Errorf("favorites = %v, want %d", msg.places.Favorites, tt.pinned)
+			}
+		})
+	}
+}
+
+func TestRerunJobPickedFromTheRuns(t *testing.T) {
+	fake := process.NewFake()
+	m := NewModel(fake, &process.Cache{Dir: t.TempDir()}, &state{})
+	m.organization.Selected = process.Result{Name: "acme"}
+	m.repository.Selected = process.Result{Name: "web"}
+	m.mode = modeStack{Environment, Workflow}
+	run := fake.WorkflowRuns["acme/web"][0]
+
+	m = update(m, workflow.RerunJobMsg{Payload: run})
+	m = update(m, m.getJobsCmd(run.ID, true)())
+	if want := (modeStack{Environment, Workflow, Loading, Job}); !slices.Equal(m.mode, want) {
+		t.Fatalf("modes while picking = %v, want %v", m.mode, want)
+	}
+
+	// enter picks the selected job, y confirms rerunning it
+	var cmd tea.Cmd
+	m.job, _ = m.job.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.job, cmd = m.job.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatal("picking a job didn't ask to rerun it")
+	}
+	rerun, ok := cmd().(job.RerunMsg)
+	if !ok || !rerun.Picked {
+		t.Fatalf("confirming = %#v, want a picked rerun", rerun)
+	}
+
+	m = update(m, m.rerunJobCmd(rerun)())
+	if want := (modeStack{Environment, Workflow}); !slices.Equal(m.mode, want) {
+		t.Errorf("modes after the rerun = %v, want %v", m.mode, want)
+	}
+	if want := []string{"rerun job 5000"}; !slices.Equal(fake.Actions, want) {
+		t.Errorf("actions = %v, want %v", fake.Actions, want)
+	}
+}
@@ -1,8 +1,8 @@
 package organization
 
 import (
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 
 	"github.com/real-erik/platui/process"
 	"github.com/real-erik/platui/tui/list"
@@ -11,15 +11,32 @@ import (
 type Model struct {
 	list     list.Model
 	items    []process.Result
+	notice   string
+	height   int
+	width    int
 	Selected process.Result
 }
 
+var pinKey = key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin"))
+
 func NewModel() Model {
+	list := list.NewModel("Organizations")
+	list.AddKeys(pinKey)
+
 	return Model{
-		list: list.NewModel("Organizations"),
+		list: list,
 	}
 }
 
+// Notice is shown above the list until the next key press.
+type Notice string
+
+// PinMsg asks for an organization to be pinned to the favorites, or unpinned
+// when it already is.
+type PinMsg struct {
+	Payload process.Result
+}
+
 type BackMsg struct{}
 
 type ForwardMsg struct {
@@ -30,10 +47,21 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.notice)
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list, _ = m.list.Update(msg)
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
 		return m, nil
 
 	case []process.Result:
@@ -41,12 +69,32 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		items := []list.Item{}
 		for _, resultItem := range msg {
 			newItem := list.Item{
-				Title: resultItem.Name,
+				Title:       resultItem.Name,
+				Description: resultItem.Title,
 			}
 			items = append(items, newItem)
 		}
+		m.notice = ""
 		m.list, _ = m.list.Update(items)
+		m.resizeList()
 		return m, nil
+
+	case tea.KeyMsg:
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if !m.list.Filtering() && key.Matches(msg, pinKey) {
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			organization := m.items[selected]
+			return m, func() tea.Msg {
+				return PinMsg{Payload: organization}
+			}
+		}
 	}
 
 	var cmd tea.Cmd
@@ -85,5 +133,5 @@ type item struct {
 }
 
 func (m Model) View() string {
-	return lipgloss.NewStyle().Render(m.list.View())
+	return m.list.ViewWithBar(m.notice)
 }
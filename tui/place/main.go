@@ -0,0 +1,254 @@
+package place
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/tui/format"
+	"github.com/real-erik/platui/tui/list"
+)
+
+// Place is somewhere the user can go straight to: an organization, one of
+// its repositories, a workflow of that repository or one of its runs. Owner
+// is set when the repository belongs to another account than Organization.
+type Place struct {
+	Organization string    `json:"organization"`
+	Owner        string    `json:"owner,omitempty"`
+	Repository   string    `json:"repository,omitempty"`
+	WorkflowID   int64     `json:"workflow_id,omitempty"`
+	Workflow     string    `json:"workflow,omitempty"`
+	RunID        int64     `json:"run_id,omitempty"`
+	Run          string    `json:"run,omitempty"`
+	VisitedAt    time.Time `json:"visited_at"`
+}
+
+// Same reports whether both lead to the same place, however they're named.
+func (p Place) Same(other Place) bool {
+	return p.Organization == other.Organization && p.Owner == other.Owner &&
+		p.Repository == other.Repository && p.WorkflowID == other.WorkflowID && p.RunID == other.RunID
+}
+
+// Name is the path to the place, e.g. acme/web · Playwright · #412 Fix login.
+func (p Place) Name() string {
+	if p.Repository == "" {
+		return p.Organization
+	}
+
+	owner := p.Owner
+	if owner == "" {
+		owner = p.Organization
+	}
+	name := owner + "/" + p.Repository
+	if p.Workflow != "" {
+		name += " · " + p.Workflow
+	}
+	if p.RunID != 0 {
+		name += " · " + p.Run
+	}
+
+	return name
+}
+
+func (p Place) kind() string {
+	switch {
+	case p.RunID != 0:
+		return "run"
+	case p.WorkflowID != 0:
+		return "workflow"
+	case p.Repository != "":
+		return "repository"
+	}
+
+	return "organization"
+}
+
+type Kind int
+
+const (
+	Favorites Kind = iota
+	Recent
+)
+
+// Places are the favorites and the recently visited places, most recent
+// first. They are sent again whenever they change.
+type Places struct {
+	Favorites []Place
+	Recent    []Place
+}
+
+// Show lists the favorites or the recent places.
+type Show Kind
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+// PinMsg asks for a place to be pinned to the favorites, or unpinned when it
+// already is.
+type PinMsg struct {
+	Payload Place
+}
+
+type BackMsg struct{}
+
+type ForwardMsg struct {
+	Payload Place
+}
+
+type Model struct {
+	list    list.Model
+	places  Places
+	showing Kind
+	items   []Place
+	notice  string
+	height  int
+	width   int
+}
+
+var pinKey = key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin"))
+
+func NewModel() Model {
+	list := list.NewModel("Favorites")
+	list.AddKeys(pinKey)
+
+	return Model{
+		list: list,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) pinned(p Place) bool {
+	for _, favorite := range m.places.Favorites {
+		if favorite.Same(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m Model) description(p Place) string {
+	desc := p.kind()
+	if m.showing == Recent {
+		if visited := format.Ago(p.VisitedAt); visited != "" {
+			desc += " · visited " + visited
+		}
+		if m.pinned(p) {
+			desc += " · pinned"
+		}
+	}
+
+	return desc
+}
+
+// show lists the places of kind. Unless reset is set, the cursor and filter
+// are kept as long as the number of places stayed the same.
+func (m Model) show(kind Kind, reset bool) (Model, tea.Cmd) {
+	previous := len(m.items)
+	m.showing = kind
+	m.items = m.places.Favorites
+	m.list.SetTitle("Favorites")
+	if kind == Recent {
+		m.items = m.places.Recent
+		m.list.SetTitle("Recent")
+	}
+
+	items := []list.Item{}
+	for _, p := range m.items {
+		items = append(items, list.Item{
+			Title:       p.Name(),
+			Description: m.description(p),
+		})
+	}
+
+	if reset || len(m.items) != previous {
+		m.list, _ = m.list.Update(items)
+		m.resizeList()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(list.RefreshMsg(items))
+	return m, cmd
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.notice)
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case Places:
+		m.places = msg
+		return m.show(m.showing, false)
+
+	case Show:
+		m.notice = ""
+		return m.show(Kind(msg), true)
+
+	case tea.KeyMsg:
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if !m.list.Filtering() && key.Matches(msg, pinKey) {
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			p := m.items[selected]
+			return m, func() tea.Msg {
+				return PinMsg{Payload: p}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	if cmd != nil {
+		listMsg := cmd()
+		switch listMsg.(type) {
+		case list.Msg:
+			listMsg := listMsg.(list.Msg)
+			switch listMsg.Direction {
+			case list.Forward:
+				p := m.items[listMsg.Item]
+				cmd = func() tea.Msg {
+					return ForwardMsg{
+						Payload: p,
+					}
+				}
+
+			case list.Back:
+				cmd = func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		default:
+			// this is a command from bubbletea list, let it pass through
+		}
+	}
+
+	return m, cmd
+
+}
+
+func (m Model) View() string {
+	return m.list.ViewWithBar(m.notice)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/tui/download"
+)
+
+// progressUpdates hands the progress of downloads over to the UI. Only the
+// latest progress of every artifact is kept until the UI picks it up, so a
+// busy UI skips updates in between but never misses the last one.
+type progressUpdates struct {
+	mu     sync.Mutex
+	latest map[int64]download.Progress
+	// ready holds a token whenever there is progress to pick up
+	ready chan struct{}
+	done  chan struct{}
+}
+
+func newProgressUpdates() *progressUpdates {
+	return &progressUpdates{
+		latest: map[int64]download.Progress{},
+		ready:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// report replaces the progress of p's artifact that wasn't picked up yet.
+func (u *progressUpdates) report(p download.Progress) {
+	u.mu.Lock()
+	u.latest[p.ArtifactID] = p
+	u.mu.Unlock()
+
+	select {
+	case u.ready <- struct{}{}:
+	default:
+	}
+}
+
+// close is called once the download is over, nothing may be reported after.
+func (u *progressUpdates) close() {
+	close(u.done)
+}
+
+func (u *progressUpdates) take() []download.Progress {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var progress []download.Progress
+	for _, p := range u.latest {
+		progress = append(progress, p)
+	}
+	clear(u.latest)
+
+	return progress
+}
+
+// wait blocks until there is progress to pick up and returns it, or nil once
+// the download is over and all of it was picked up.
+func (u *progressUpdates) wait() []download.Progress {
+	for {
+		select {
+		case <-u.ready:
+		case <-u.done:
+		}
+
+		if progress := u.take(); len(progress) > 0 {
+			return progress
+		}
+
+		select {
+		case <-u.done:
+			return nil
+		default:
+		}
+	}
+}
+
+func waitForProgressCmd(updates *progressUpdates) tea.Cmd {
+	return func() tea.Msg {
+		progress := updates.wait()
+		if progress == nil {
+			return nil
+		}
+
+		return downloadProgressMsg{Payload: progress, updates: updates}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/real-erik/platui/tui/download"
+)
+
+func TestProgressUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		reports []download.Progress
+		want    []download.Progress
+	}{
+		{"nothing", nil, nil},
+		{
+			"latest of each artifact",
+			[]download.Progress{{ArtifactID: 1, Done: 1, Total: 10}, {ArtifactID: 2, Done: 5, Total: 5}, {ArtifactID: 1, Done: 10, Total: 10}},
+			[]download.Progress{{ArtifactID: 1, Done: 10, Total: 10}, {ArtifactID: 2, Done: 5, Total: 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := newProgressUpdates()
+			for _, p := range tt.reports {
+				updates.report(p)
+			}
+			updates.close()
+
+			got := updates.wait()
+			slices.SortFunc(got, func(a, b download.Progress) int {
+				return int(a.ArtifactID - b.ArtifactID)
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("wait() = %v, want %v", got, tt.want)
+			}
+			if got != nil {
+				if rest := updates.wait(); rest != nil {
+					t.Fatalf("wait() after everything was picked up = %v, want nil", rest)
+				}
+			}
+		})
+	}
+}
+
+func TestProgressUpdatesKeepsLastAfterPickup(t *testing.T) {
+	updates := newProgressUpdates()
+	updates.report(download.Progress{ArtifactID: 1, Done: 1, Total: 2})
+	if got := updates.wait(); len(got) != 1 || got[0].Done != 1 {
+		t.Fatalf("wait() = %v", got)
+	}
+
+	// the ready token of the first report is gone, the last one must still arrive
+	updates.report(download.Progress{ArtifactID: 1, Done: 2, Total: 2})
+	updates.close()
+	if got := updates.wait(); len(got) != 1 || got[0].Done != 2 {
+		t.Fatalf("wait() = %v, want the final update", got)
+	}
+}
@@ -1,24 +1,92 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/format"
 	"github.com/real-erik/platui/tui/list"
 )
 
+// Model lists repositories by name or, with byActivity, most recently pushed
+// first, which while partial is of the repositories loaded so far. Archived repositories are hidden unless showArchived is set. While
+// more pages are loading, a typed filter is also searched for on the server,
+// unless noSearch says the server can't search these repositories.
 type Model struct {
-	list     list.Model
-	items    []process.Result
-	Selected process.Result
+	list         list.Model
+	all          []process.Result
+	items        []process.Result
+	byActivity   bool
+	showArchived bool
+	loading      bool
+	partial      bool
+	noSearch     bool
+	status       string
+	query        string
+	notice       string
+	height       int
+	width        int
+	Selected     process.Result
 }
 
+var (
+	sortKey     = key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort by activity"))
+	archivedKey = key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "show archived"))
+	pinKey      = key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin"))
+)
+
 func NewModel() Model {
+	list := list.NewModel("Repositories")
+	list.AddKeys(sortKey, archivedKey, pinKey)
+
 	return Model{
-		list: list.NewModel("Repositories"),
+		list: list,
 	}
 }
 
+// Page is a page of repositories. NextPage is 0 when there are no more to
+// load. With Append set the repositories are added to the list instead of
+// replacing it.
+type Page struct {
+	Items    []process.Result
+	NextPage int
+	Append   bool
+	Err      error
+}
+
+// Found adds repositories found by searching that weren't loaded yet.
+type Found struct {
+	Items []process.Result
+	Err   error
+}
+
+// SearchMsg asks for repositories whose name matches Query.
+type SearchMsg struct {
+	Query string
+}
+
+// searchDelay waits for the user to stop typing before searching.
+const searchDelay = 300 * time.Millisecond
+
+type searchAfterMsg struct {
+	query string
+}
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+// PinMsg asks for a repository to be pinned to the favorites, or unpinned
+// when it already is.
+type PinMsg struct {
+	Payload process.Result
+}
+
 type BackMsg struct{}
 
 type ForwardMsg struct {
@@ -29,24 +97,184 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+func description(repository process.Result) string {
+	var parts []string
+	if repository.Archived {
+		parts = append(parts, "archived")
+	}
+	for _, part := range []string{repository.Visibility, repository.Branch} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if pushed := format.Ago(repository.PushedAt); pushed != "" {
+		parts = append(parts, "pushed "+pushed)
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+func (m Model) title() string {
+	title := "Repositories"
+	switch {
+	// the server sorts by name, those not loaded yet may have been pushed to
+	// more recently
+	case m.byActivity && m.partial:
+		title += " · by activity of those loaded so far"
+	case m.byActivity:
+		title += " · by activity"
+	}
+	if hidden := len(m.all) - len(m.items); hidden > 0 {
+		title += fmt.Sprintf(" · %d archived hidden", hidden)
+	}
+	if m.status != "" {
+		title += " · " + m.status
+	}
+
+	return title
+}
+
+// show lists the repositories again after sorting, toggling archived
+// repositories or loading more. The order of all is the server's, which is by
+// name. Unless reset is set, the cursor and filter are kept.
+func (m Model) show(reset bool) (Model, tea.Cmd) {
+	m.items = nil
+	for _, repository := range m.all {
+		if m.showArchived || !repository.Archived {
+			m.items = append(m.items, repository)
+		}
+	}
+	if m.byActivity {
+		slices.SortStableFunc(m.items, func(a process.Result, b process.Result) int {
+			return b.PushedAt.Compare(a.PushedAt)
+		})
+	}
+
+	items := []list.Item{}
+	for _, resultItem := range m.items {
+		newItem := list.Item{
+			Title:       resultItem.Name,
+			Description: description(resultItem),
+		}
+		// repositories of someone else go by their full name
+		if resultItem.Title != "" {
+			newItem.Title = resultItem.Title
+		}
+		items = append(items, newItem)
+	}
+	m.list.SetTitle(m.title())
+	if reset {
+		m.list, _ = m.list.Update(items)
+		m.resizeList()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(list.RefreshMsg(items))
+	return m, cmd
+}
+
+// add appends the repositories that aren't listed yet, a search may have
+// found them before their page was loaded.
+func (m Model) add(repositories []process.Result) Model {
+	for _, repository := range repositories {
+		if !slices.ContainsFunc(m.all, func(r process.Result) bool { return r.ID == repository.ID }) {
+			m.all = append(m.all, repository)
+		}
+	}
+
+	return m
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.notice)
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list, _ = m.list.Update(msg)
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
 		return m, nil
 
-	case []process.Result:
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case Page:
+		m.loading = msg.NextPage != 0 && msg.Err == nil
+		m.partial = msg.NextPage != 0 || msg.Err != nil
+		m.status = ""
+		switch {
+		case msg.Err != nil:
+			m.status = "failed to load all repositories"
+		case m.loading:
+			m.status = "loading more repositories..."
+		}
 
-		m.items = msg
-		items := []list.Item{}
-		for _, resultItem := range m.items {
-			newItem := list.Item{
-				Title: resultItem.Name,
+		if !msg.Append {
+			m.all = msg.Items
+			m.noSearch = false
+			m.query = ""
+			m.notice = ""
+			return m.show(true)
+		}
+		m = m.add(msg.Items)
+		return m.show(false)
+
+	case Found:
+		// filtering what is loaded is all there is
+		if errors.Is(msg.Err, process.ErrNoSearch) {
+			m.noSearch = true
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.status = "search failed"
+			m.list.SetTitle(m.title())
+			return m, nil
+		}
+		m = m.add(msg.Items)
+		return m.show(false)
+
+	case searchAfterMsg:
+		if !m.loading || msg.query != strings.TrimSpace(m.list.FilterValue()) {
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			return SearchMsg{Query: msg.query}
+		}
+
+	case tea.KeyMsg:
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if m.list.Filtering() {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, sortKey):
+			m.byActivity = !m.byActivity
+			return m.show(false)
+
+		case key.Matches(msg, archivedKey):
+			m.showArchived = !m.showArchived
+			return m.show(false)
+
+		case key.Matches(msg, pinKey):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			repository := m.items[selected]
+			return m, func() tea.Msg {
+				return PinMsg{Payload: repository}
 			}
-			items = append(items, newItem)
 		}
-		m.list, _ = m.list.Update(items)
-		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -76,6 +304,14 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 	}
 
+	// repositories that aren't loaded yet can only be found by searching
+	if query := strings.TrimSpace(m.list.FilterValue()); m.loading && !m.noSearch && query != "" && query != m.query {
+		m.query = query
+		cmd = tea.Batch(cmd, tea.Tick(searchDelay, func(time.Time) tea.Msg {
+			return searchAfterMsg{query: query}
+		}))
+	}
+
 	return m, cmd
 
 }
@@ -85,5 +321,5 @@ type item struct {
 }
 
 func (m Model) View() string {
-	return lipgloss.NewStyle().Render(m.list.View())
+	return m.list.ViewWithBar(m.notice)
 }
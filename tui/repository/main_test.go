@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/real-erik/platui/process"
+)
+
+func TestSearchWhileLoading(t *testing.T) {
+	key := func(s string) tea.KeyMsg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	tests := []struct {
+		name       string
+		found      error
+		wantQuery  string
+		wantStatus string
+	}{
+		{"searched", nil, "we", "loading more repositories..."},
+		{"search failed", errors.New("timeout"), "we", "search failed"},
+		{"can't be searched", process.ErrNoSearch, "w", "loading more repositories..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+			m, _ = m.Update(Page{Items: []process.Result{{ID: 1, Name: "api"}}, NextPage: 2})
+
+			m, _ = m.Update(key("/"))
+			m, _ = m.Update(key("w"))
+			m, _ = m.Update(Found{Err: tt.found})
+			m, _ = m.Update(key("e"))
+
+			if m.query != tt.wantQuery {
+				t.Errorf("searched for %q, want %q", m.query, tt.wantQuery)
+			}
+			if m.status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", m.status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSortByActivityWhileLoading(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page
+		want string
+	}{
+		{"loading", Page{NextPage: 2}, "Repositories · by activity of those loaded so far · loading more repositories..."},
+		{"loaded", Page{}, "Repositories · by activity"},
+		{"failed to load", Page{Err: errors.New("timeout")}, "Repositories · by activity of those loaded so far · failed to load all repositories"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(tt.page)
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+
+			if got := m.title(); got != tt.want {
+				t.Fatalf("title() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
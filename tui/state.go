@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/real-erik/platui/tui/place"
+)
+
+// maxRecent is how many places are remembered as recently visited.
+const maxRecent = 20
+
+// state is what platui remembers between runs, kept in platui/state.json in
+// the user's state directory. Without a path it is only kept in memory. It is
+// saved from commands, mu keeps them from changing it at the same time.
+type state struct {
+	Favorites []place.Place `json:"favorites"`
+	Recent    []place.Place `json:"recent"`
+	path      string
+	mu        sync.Mutex
+}
+
+func statePath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, "platui", "state.json")
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{path: path}
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// update applies change to what is in the state file right now and saves
+// it, so another instance of platui doesn't lose what it saved in between.
+// The state in memory is used when the file can't be read.
+func (s *state) update(change func(*state)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path != "" {
+		if current, err := loadState(s.path); err == nil {
+			s.Favorites = current.Favorites
+			s.Recent = current.Recent
+		}
+	}
+
+	change(s)
+	return s.save()
+}
+
+// save replaces the state file at once, so a crash can't leave half of it.
+// Each save writes a temporary file of its own next to it.
+func (s *state) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *state) places() place.Places {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return place.Places{Favorites: s.Favorites, Recent: s.Recent}
+}
+
+// togglePin pins p to the favorites, or unpins it when it already is, and
+// reports whether it is pinned now.
+func (s *state) togglePin(p place.Place) (bool, error) {
+	var pinned bool
+	err := s.update(func(s *state) {
+		if i := slices.IndexFunc(s.Favorites, p.Same); i >= 0 {
+			s.Favorites = slices.Delete(slices.Clone(s.Favorites), i, i+1)
+			pinned = false
+			return
+		}
+
+		p.VisitedAt = time.Time{}
+		s.Favorites = append(slices.Clone(s.Favorites), p)
+		pinned = true
+	})
+
+	return pinned, err
+}
+
+// visit moves p to the top of the recent places.
+func (s *state) visit(p place.Place) error {
+	p.VisitedAt = time.Now()
+
+	return s.update(func(s *state) {
+		recent := slices.DeleteFunc(slices.Clone(s.Recent), p.Same)
+		s.Recent = append([]place.Place{p}, recent...)
+		if len(s.Recent) > maxRecent {
+			s.Recent = s.Recent[:maxRecent]
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/real-erik/platui/tui/place"
+)
+
+func names(places []place.Place) []string {
+	var names []string
+	for _, p := range places {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+func TestTogglePin(t *testing.T) {
+	acme := place.Place{Organization: "acme"}
+	web := place.Place{Organization: "acme", Repository: "web"}
+
+	tests := []struct {
+		name   string
+		pins   []place.Place
+		pinned bool
+		want   []string
+	}{
+		{"pin", []place.Place{acme}, true, []string{"acme"}},
+		{"pin another", []place.Place{acme, web}, true, []string{"acme", "acme/web"}},
+		{"unpin", []place.Place{acme, web, acme}, false, []string{"acme/web"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "platui", "state.json")
+			s, err := loadState(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var pinned bool
+			for _, p := range tt.pins {
+				if pinned, err = s.togglePin(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if pinned != tt.pinned {
+				t.Errorf("pinned = %v, want %v", pinned, tt.pinned)
+			}
+
+			saved, err := loadState(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(names(saved.Favorites), tt.want) {
+				t.Errorf("saved favorites = %v, want %v", names(saved.Favorites), tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveKeepsWhatAnotherInstanceSaved(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	first, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := first.togglePin(place.Place{Organization: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.visit(place.Place{Organization: "acme", Repository: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.visit(place.Place{Organization: "acme", Repository: "api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := loadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"acme"}; !slices.Equal(names(saved.Favorites), want) {
+		t.Errorf("favorites = %v, want %v", names(saved.Favorites), want)
+	}
+	if want := []string{"acme/api", "acme/web"}; !slices.Equal(names(saved.Recent), want) {
+		t.Errorf("recent = %v, want %v", names(saved.Recent), want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("left %d files next to the state, want only state.json", len(entries)-1)
+	}
+}
@@ -0,0 +1,101 @@
+package step
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/list"
+	"github.com/real-erik/platui/tui/styles"
+)
+
+type Model struct {
+	list  list.Model
+	items []process.Step
+	job   process.Job
+}
+
+func NewModel() Model {
+	return Model{
+		list: list.NewModel("Steps"),
+	}
+}
+
+type BackMsg struct{}
+
+// LogMsg asks for the log of the job the steps belong to.
+type LogMsg struct {
+	Payload process.Job
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func description(step process.Step) string {
+	desc := step.Status
+	if step.Conclusion != "" {
+		desc = step.Conclusion
+	}
+	if duration := step.Duration(); duration > 0 {
+		desc += " · " + duration.Round(time.Second).String()
+	}
+
+	return desc
+}
+
+// Update takes the job whose steps should be shown.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list, _ = m.list.Update(msg)
+		return m, nil
+
+	case process.Job:
+		m.job = msg
+		m.items = msg.Steps
+		items := []list.Item{}
+		for _, resultItem := range m.items {
+			newItem := list.Item{
+				Title:       fmt.Sprintf("%s %d. %s", styles.StatusToColor(resultItem.Status, resultItem.Conclusion), resultItem.Number, resultItem.Name),
+				Description: description(resultItem),
+			}
+			items = append(items, newItem)
+		}
+		m.list.SetTitle("Steps · " + msg.Name)
+		m.list, _ = m.list.Update(items)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	if cmd != nil {
+		listMsg := cmd()
+		switch listMsg.(type) {
+		case list.Msg:
+			listMsg := listMsg.(list.Msg)
+			switch listMsg.Direction {
+			case list.Forward:
+				cmd = func() tea.Msg {
+					return LogMsg{Payload: m.job}
+				}
+			case list.Back:
+				cmd = func() tea.Msg {
+					return BackMsg{}
+				}
+			}
+		default:
+			// this is a command from bubbletea list, let it pass through
+		}
+	}
+
+	return m, cmd
+
+}
+
+func (m Model) View() string {
+	return lipgloss.NewStyle().Render(m.list.View())
+}
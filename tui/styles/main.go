@@ -5,3 +5,41 @@ import (
 )
 
 var DocStyle = lipgloss.NewStyle().Margin(1, 2)
+
+var TitleStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FFFDF5")).
+	Background(lipgloss.Color("#25A065")).
+	Padding(0, 1)
+
+var ErrorTitleStyle = TitleStyle.Background(lipgloss.Color("#E8465A"))
+
+var ErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#E8465A"))
+
+var HelpStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#B2B2B2", Dark: "#4A4A4A"})
+
+func ConclusionToColor(conclusion string) string {
+	switch conclusion {
+	case "success":
+		return "🟢"
+	case "failure":
+		return "🔴"
+	case "cancelled":
+		return "⚪"
+	case "skipped":
+		return "🔳"
+	case "in_progress":
+		return "🟡"
+	}
+
+	return ""
+}
+
+// StatusToColor falls back to the status for runs, jobs and steps that have
+// not concluded yet.
+func StatusToColor(status string, conclusion string) string {
+	if conclusion != "" {
+		return ConclusionToColor(conclusion)
+	}
+
+	return ConclusionToColor(status)
+}
@@ -1,67 +1,445 @@
 package workflow
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/real-erik/platui/process"
+	"github.com/real-erik/platui/tui/confirm"
+	"github.com/real-erik/platui/tui/format"
 	"github.com/real-erik/platui/tui/list"
+	"github.com/real-erik/platui/tui/styles"
 )
 
 type Model struct {
-	list    list.Model
-	items   []process.Result
+	list        list.Model
+	items       []process.Run
+	nextPage    int
+	loadingMore bool
+	status      string
+	workflow    string
+	Filter      process.RunFilter
+	filterBar   textinput.Model
+	filtering   bool
+	filterErr   error
+	confirm     confirm.Model
+	notice      string
+	height      int
+	width       int
+	Selected    process.Run
+}
+
+type keyMap struct {
+	filter      key.Binding
+	jobs        key.Binding
+	rerun       key.Binding
+	rerunJob    key.Binding
+	rerunFailed key.Binding
+	cancel      key.Binding
+	downloadAll key.Binding
+	pin         key.Binding
+}
+
+var keys = keyMap{
+	filter:      key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "filter runs")),
+	jobs:        key.NewBinding(key.WithKeys("J"), key.WithHelp("J", "jobs")),
+	rerun:       key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "rerun")),
+	rerunJob:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "rerun a job")),
+	rerunFailed: key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "rerun failed")),
+	cancel:      key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "cancel")),
+	downloadAll: key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "download all artifacts")),
+	pin:         key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin/unpin")),
 }
 
 func NewModel() Model {
+	filterBar := textinput.New()
+	filterBar.Prompt = "Filter: "
+	filterBar.Placeholder = "branch:main event:push actor:@me status:failure created:>=2024-06-01"
+
+	list := list.NewModel("Runs")
+	list.AddKeys(keys.filter, keys.jobs, keys.rerun, keys.rerunJob, keys.rerunFailed, keys.cancel, keys.downloadAll, keys.pin)
+
 	return Model{
-		list:    list.NewModel("Workflows"),
+		list:      list,
+		filterBar: filterBar,
+		confirm:   confirm.NewModel(),
 	}
 }
 
+type Action int
+
+const (
+	Rerun Action = iota
+	RerunFailed
+	Cancel
+)
+
+// ActionMsg is sent once the user confirmed an action on a run.
+type ActionMsg struct {
+	Action  Action
+	Payload process.Run
+}
+
+// Notice is shown above the list until the next key press.
+type Notice string
+
+// JobsMsg asks for the jobs of a run.
+type JobsMsg struct {
+	Payload process.Run
+}
+
+// RerunJobMsg asks for the jobs of a run to pick one to rerun.
+type RerunJobMsg struct {
+	Payload process.Run
+}
+
+// DownloadAllMsg asks for every artifact of a run to be downloaded.
+type DownloadAllMsg struct {
+	Payload process.Run
+}
+
+// PinMsg asks for a run to be pinned to the favorites, or unpinned when it
+// already is.
+type PinMsg struct {
+	Payload process.Run
+}
+
+// FilterMsg asks for the runs to be queried again with a new filter.
+type FilterMsg struct {
+	Filter process.RunFilter
+}
+
+// ClearFilter drops the filter before the runs of another workflow are
+// loaded.
+type ClearFilter struct{}
+
 type BackMsg struct{}
 
+// Page is a page of workflow runs. NextPage is 0 when there are no more runs
+// to load. With Append set the runs are added to the current list instead of
+// replacing it, otherwise Workflow names the workflow the runs belong to.
+type Page struct {
+	Items    []process.Run
+	NextPage int
+	Append   bool
+	Workflow string
+	Err      error
+}
+
+// LoadMoreMsg asks for the next page of runs once the cursor reaches the
+// bottom of the list.
+type LoadMoreMsg struct {
+	Page int
+}
+
 type ForwardMsg struct {
-	Payload process.Result
+	Payload process.Run
 }
 
 func (m Model) Init() tea.Cmd {
 	return nil
 }
 
-func conclusionToColor(conclusion string) string {
-	switch conclusion {
-	case "success":
-		return "🟢"
-	case "failure":
-		return "🔴"
-	case "cancelled":
-		return "⚪"
-	case "skipped":
-		return "🔳"
-	case "in_progress":
-		return "🟡"
+var statuses = []string{
+	"completed", "action_required", "cancelled", "failure", "neutral",
+	"skipped", "stale", "success", "timed_out", "in_progress", "queued",
+	"requested", "waiting", "pending",
+}
+
+func parseFilter(s string) (process.RunFilter, error) {
+	var filter process.RunFilter
+	for _, field := range strings.Fields(s) {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			return filter, fmt.Errorf("expected key:value, got %q", field)
+		}
+
+		switch key {
+		case "branch":
+			filter.Branch = value
+		case "event":
+			filter.Event = value
+		case "actor":
+			filter.Actor = value
+		case "status":
+			if !slices.Contains(statuses, value) {
+				return filter, fmt.Errorf("unknown status %q", value)
+			}
+			filter.Status = value
+		case "created":
+			filter.Created = value
+		default:
+			return filter, fmt.Errorf("unknown filter %q", key)
+		}
+	}
+
+	return filter, nil
+}
+
+func (m *Model) updateTitle() {
+	title := "Runs"
+	if m.workflow != "" {
+		title += " · " + m.workflow
+	}
+	if !m.Filter.IsZero() {
+		title += " · " + m.Filter.String()
+	}
+	if m.status != "" {
+		title += " · " + m.status
+	}
+	m.list.SetTitle(title)
+}
+
+func (m *Model) resizeList() {
+	m.list = m.list.Resize(m.width, m.height, m.bar())
+}
+
+func (m Model) updateFilterBar(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		filter, err := parseFilter(m.filterBar.Value())
+		if err != nil {
+			m.filterErr = err
+			return m, nil
+		}
+
+		m.filtering = false
+		m.filterErr = nil
+		m.filterBar.Blur()
+		m.Filter = filter
+		m.updateTitle()
+		m.resizeList()
+		return m, func() tea.Msg {
+			return FilterMsg{Filter: filter}
+		}
+
+	case "esc":
+		m.filtering = false
+		m.filterErr = nil
+		m.filterBar.Blur()
+		m.resizeList()
+		return m, nil
 	}
 
-	return ""
+	var cmd tea.Cmd
+	m.filterBar, cmd = m.filterBar.Update(msg)
+	return m, cmd
+}
+
+// columns lay the run's metadata out so runs line up below each other. The
+// workflow name is only shown when listing the runs of every workflow.
+func (m Model) columns(run process.Run) []list.Column {
+	columns := []list.Column{
+		{Text: fmt.Sprintf("#%d", run.RunNumber), Width: 6},
+		{Text: fmt.Sprintf("attempt %d", max(run.Attempt, 1)), Width: 10},
+		{Text: run.Branch, Width: 20},
+		{Text: run.Event, Width: 18},
+		{Text: "@" + run.Actor, Width: 18},
+		{Text: format.Ago(run.StartedAt), Width: 10},
+		{Text: duration(run), Width: 10},
+	}
+	if run.Name != m.workflow {
+		columns = append([]list.Column{{Text: run.Name, Width: 16}}, columns...)
+	}
+
+	return columns
+}
+
+func duration(run process.Run) string {
+	d := run.Duration()
+	if d <= 0 {
+		return ""
+	}
+
+	return d.Round(time.Second).String()
+}
+
+// ask confirms an action on the selected run, refusing actions that don't
+// apply to the run's current state.
+func (m Model) ask(action Action) Model {
+	selected, ok := m.list.Selected()
+	if !ok {
+		return m
+	}
+	run := m.items[selected]
+	done := run.Status == "completed"
+
+	var question string
+	switch {
+	case action == Rerun && done:
+		question = fmt.Sprintf("Rerun all jobs of %q?", run.Title)
+	case action == RerunFailed && done && (run.Conclusion == "failure" || run.Conclusion == "cancelled"):
+		question = fmt.Sprintf("Rerun failed jobs of %q?", run.Title)
+	case action == RerunFailed && done:
+		m.notice = "Only failed or cancelled runs have failed jobs to rerun."
+		return m
+	case action == Cancel && !done:
+		question = fmt.Sprintf("Cancel %q?", run.Title)
+	case action == Cancel:
+		m.notice = "Only runs that haven't completed can be cancelled."
+		return m
+	default:
+		m.notice = "Only completed runs can be rerun."
+		return m
+	}
+
+	m.confirm = m.confirm.Ask(question, ActionMsg{Action: action, Payload: run})
+	return m
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.list, _ = m.list.Update(msg)
+		m.height = msg.Height
+		m.width = msg.Width
+		m.resizeList()
+		return m, nil
+
+	case Notice:
+		m.notice = string(msg)
+		m.resizeList()
+		return m, nil
+
+	case ClearFilter:
+		m.Filter = process.RunFilter{}
+		m.filtering = false
+		m.filterErr = nil
+		m.filterBar.Blur()
+		m.filterBar.SetValue("")
+		m.updateTitle()
+		m.resizeList()
 		return m, nil
 
-	case []process.Result:
-		m.items = msg
+	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilterBar(msg)
+		}
+
+		if m.confirm.Active() {
+			var cmd tea.Cmd
+			m.confirm, cmd = m.confirm.Update(msg)
+			m.resizeList()
+			return m, cmd
+		}
+
+		if m.notice != "" {
+			m.notice = ""
+			m.resizeList()
+		}
+
+		if m.list.Filtering() {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, keys.filter):
+			m.filtering = true
+			m.filterBar.SetValue(m.Filter.String())
+			m.filterBar.CursorEnd()
+			m.resizeList()
+			return m, m.filterBar.Focus()
+
+		case key.Matches(msg, keys.jobs):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			m.Selected = m.items[selected]
+			return m, func() tea.Msg {
+				return JobsMsg{Payload: m.Selected}
+			}
+
+		case key.Matches(msg, keys.rerunJob):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			run := m.items[selected]
+			if run.Status != "completed" {
+				m.notice = "Only completed runs can be rerun."
+				m.resizeList()
+				return m, nil
+			}
+			m.Selected = run
+			return m, func() tea.Msg {
+				return RerunJobMsg{Payload: run}
+			}
+
+		case key.Matches(msg, keys.downloadAll):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			m.Selected = m.items[selected]
+			return m, func() tea.Msg {
+				return DownloadAllMsg{Payload: m.Selected}
+			}
+
+		case key.Matches(msg, keys.pin):
+			selected, ok := m.list.Selected()
+			if !ok {
+				return m, nil
+			}
+			run := m.items[selected]
+			return m, func() tea.Msg {
+				return PinMsg{Payload: run}
+			}
+
+		case key.Matches(msg, keys.rerun):
+			m = m.ask(Rerun)
+			m.resizeList()
+			return m, nil
+
+		case key.Matches(msg, keys.rerunFailed):
+			m = m.ask(RerunFailed)
+			m.resizeList()
+			return m, nil
+
+		case key.Matches(msg, keys.cancel):
+			m = m.ask(Cancel)
+			m.resizeList()
+			return m, nil
+		}
+
+	case Page:
+		m.loadingMore = false
+		if msg.Err != nil {
+			m.status = "failed to load more runs"
+			m.updateTitle()
+			return m, nil
+		}
+
+		m.nextPage = msg.NextPage
+		m.status = ""
+		m.updateTitle()
+
+		if !msg.Append {
+			m.workflow = msg.Workflow
+		}
+
 		items := []list.Item{}
-		for _, resultItem := range m.items {
-			conclusionColor := conclusionToColor(resultItem.Conclusion)
+		for _, resultItem := range msg.Items {
+			conclusionColor := styles.StatusToColor(resultItem.Status, resultItem.Conclusion)
 			newItem := list.Item{
-				Title:       conclusionColor + " " + resultItem.Title,
-				Description: resultItem.Name,
+				Title:   conclusionColor + " " + resultItem.Title,
+				Columns: m.columns(resultItem),
 			}
 			items = append(items, newItem)
 		}
+
+		if msg.Append {
+			m.items = append(m.items, msg.Items...)
+			m.list, _ = m.list.Update(list.AppendMsg(items))
+			return m, nil
+		}
+
+		m.items = msg.Items
+		m.updateTitle()
 		m.list, _ = m.list.Update(items)
 		return m, nil
 	}
@@ -77,9 +455,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			listMsg := listMsg.(list.Msg)
 			switch listMsg.Direction {
 			case list.Forward:
+				m.Selected = m.items[listMsg.Item]
 				cmd = func() tea.Msg {
 					return ForwardMsg{
-						Payload: m.items[listMsg.Item],
+						Payload: m.Selected,
 					}
 				}
 			case list.Back:
@@ -92,6 +471,16 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 	}
 
+	if m.nextPage != 0 && !m.loadingMore && m.list.AtBottom() {
+		m.loadingMore = true
+		m.status = "loading more runs..."
+		m.updateTitle()
+		page := m.nextPage
+		cmd = tea.Batch(cmd, func() tea.Msg {
+			return LoadMoreMsg{Page: page}
+		})
+	}
+
 	return m, cmd
 
 }
@@ -100,6 +489,21 @@ type item struct {
 	title, desc string
 }
 
+func (m Model) bar() string {
+	switch {
+	case m.filtering:
+		bar := m.filterBar.View()
+		if m.filterErr != nil {
+			bar += "  " + styles.ErrorStyle.Render(m.filterErr.Error())
+		}
+		return bar
+	case m.confirm.Active():
+		return m.confirm.View()
+	}
+
+	return m.notice
+}
+
 func (m Model) View() string {
-	return lipgloss.NewStyle().Render(m.list.View())
+	return m.list.ViewWithBar(m.bar())
 }
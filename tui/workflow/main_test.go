@@ -0,0 +1,157 @@
+package workflow
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/real-erik/platui/process"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    process.RunFilter
+		wantErr bool
+	}{
+		{"", process.RunFilter{}, false},
+		{"branch:main", process.RunFilter{Branch: "main"}, false},
+		{
+			"  branch:fix-login event:push actor:@me status:failure created:>=2024-06-01 ",
+			process.RunFilter{Branch: "fix-login", Event: "push", Actor: "@me", Status: "failure", Created: ">=2024-06-01"},
+			false,
+		},
+		{"branch:feature:x", process.RunFilter{Branch: "feature:x"}, false},
+		{"branch:a branch:b", process.RunFilter{Branch: "b"}, false},
+		{"main", process.RunFilter{}, true},
+		{"branch:", process.RunFilter{}, true},
+		{"status:broken", process.RunFilter{}, true},
+		{"author:wile", process.RunFilter{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseFilter(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter(%q) error = %v, want error %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Fatalf("parseFilter(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterRoundTrip(t *testing.T) {
+	filter := process.RunFilter{Branch: "main", Event: "push", Actor: "wile", Status: "success", Created: "2024-06-01"}
+
+	got, err := parseFilter(filter.String())
+	if err != nil || got != filter {
+		t.Fatalf("parseFilter(%q) = %+v, %v, want %+v", filter.String(), got, err, filter)
+	}
+}
+
+func TestClearFilter(t *testing.T) {
+	m := NewModel()
+	m.Filter = process.RunFilter{Branch: "main"}
+	m.filterBar.SetValue("branch:main")
+
+	m, _ = m.Update(ClearFilter{})
+	if !m.Filter.IsZero() || m.filterBar.Value() != "" {
+		t.Fatalf("filter = %+v, bar = %q, want both empty", m.Filter, m.filterBar.Value())
+	}
+}
+
+func TestPageNamesWorkflow(t *testing.T) {
+	runs := []process.Run{{ID: 1, Name: "Playwright", Title: "Fix login"}}
+
+	tests := []struct {
+		name  string
+		pages []Page
+		want  string
+	}{
+		{"first page", []Page{{Items: runs, Workflow: "Playwright"}}, "Playwright"},
+		{"more runs", []Page{{Items: runs, Workflow: "Playwright"}, {Items: runs, Append: true}}, "Playwright"},
+		{"refreshed", []Page{{Items: runs, Workflow: "Playwright"}, {Items: runs, Workflow: "Playwright"}}, "Playwright"},
+		{"every workflow", []Page{{Items: runs, Workflow: "Playwright"}, {Items: runs}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			for _, page := range tt.pages {
+				m, _ = m.Update(page)
+			}
+			if m.workflow != tt.want {
+				t.Fatalf("workflow = %q, want %q", m.workflow, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsk(t *testing.T) {
+	tests := []struct {
+		name   string
+		run    process.Run
+		action Action
+		asked  bool
+	}{
+		{"rerun", process.Run{Status: "completed", Conclusion: "success"}, Rerun, true},
+		{"rerun running", process.Run{Status: "in_progress"}, Rerun, false},
+		{"rerun failed", process.Run{Status: "completed", Conclusion: "failure"}, RerunFailed, true},
+		{"rerun failed cancelled", process.Run{Status: "completed", Conclusion: "cancelled"}, RerunFailed, true},
+		{"rerun failed succeeded", process.Run{Status: "completed", Conclusion: "success"}, RerunFailed, false},
+		{"rerun failed skipped", process.Run{Status: "completed", Conclusion: "skipped"}, RerunFailed, false},
+		{"cancel", process.Run{Status: "in_progress"}, Cancel, true},
+		{"cancel completed", process.Run{Status: "completed", Conclusion: "success"}, Cancel, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+			tt.run.Title = "Fix login"
+			m, _ = m.Update(Page{Items: []process.Run{tt.run}})
+
+			m = m.ask(tt.action)
+			if m.confirm.Active() != tt.asked {
+				t.Errorf("asked = %v, want %v", m.confirm.Active(), tt.asked)
+			}
+			if !tt.asked && m.notice == "" {
+				t.Errorf("refused without telling why")
+			}
+		})
+	}
+}
+
+func TestRerunJobOnlyForCompletedRuns(t *testing.T) {
+	tests := []struct {
+		name string
+		run  process.Run
+		want bool
+	}{
+		{"completed", process.Run{ID: 1, Status: "completed", Conclusion: "failure"}, true},
+		{"running", process.Run{ID: 2, Status: "in_progress"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModel()
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+			m, _ = m.Update(Page{Items: []process.Run{tt.run}})
+
+			m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+			var msg tea.Msg
+			if cmd != nil {
+				msg = cmd()
+			}
+			rerun, ok := msg.(RerunJobMsg)
+			if ok != tt.want || (ok && rerun.Payload.ID != tt.run.ID) {
+				t.Errorf("got %#v, want a job of the run picked: %v", msg, tt.want)
+			}
+			if !ok && m.notice == "" {
+				t.Errorf("refused without telling why")
+			}
+		})
+	}
+}